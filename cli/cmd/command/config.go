@@ -0,0 +1,74 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	crawlerconfig "github.com/StevenRojas/natscrawler/crawler/config"
+	listenerconfig "github.com/StevenRojas/natscrawler/listener/config"
+	readerconfig "github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// serviceConfig configuration loader, loaded configuration and flags of a service
+type serviceConfig struct {
+	name     string
+	setup    func(*cobra.Command, []string) error
+	config   interface{}
+	addFlags func(*cobra.Command)
+}
+
+// newConfigCommand creates the command grouping the configuration commands
+func newConfigCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the services configuration",
+	}
+	command.AddCommand(newConfigCheckCommand())
+	return command
+}
+
+// newConfigCheckCommand creates the command that validates a service configuration and prints the effective one,
+// with a subcommand per service accepting its flags
+func newConfigCheckCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "check reader|listener|crawler",
+		Short: "Validate the configuration and print the effective one, with the secrets redacted",
+	}
+	for _, service := range []serviceConfig{
+		{name: "reader", setup: readerSetup, config: &readerconfig.App, addFlags: readerconfig.AddFlags},
+		{name: "listener", setup: listenerSetup, config: &listenerconfig.App, addFlags: listenerconfig.AddFlags},
+		{name: "crawler", setup: crawlerSetup, config: &crawlerconfig.App, addFlags: crawlerconfig.AddFlags},
+	} {
+		command.AddCommand(newServiceCheckCommand(service))
+	}
+	return command
+}
+
+// newServiceCheckCommand creates the command that validates the service configuration, with the flags overriding it
+func newServiceCheckCommand(service serviceConfig) *cobra.Command {
+	command := &cobra.Command{
+		Use:   service.name,
+		Short: fmt.Sprintf("Validate the %s configuration and print the effective one", service.name),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := service.setup(cmd, args)
+			var errs appconfig.Errors
+			if err != nil && !errors.As(err, &errs) {
+				return err
+			}
+			err = appconfig.Print(os.Stdout, service.config)
+			if err != nil {
+				return err
+			}
+			if len(errs) > 0 {
+				return errs
+			}
+			fmt.Println("# the configuration is valid")
+			return nil
+		},
+	}
+	service.addFlags(command)
+	return command
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"context"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	crawlerconfig "github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/repository"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand creates the command that applies the database migrations of the crawler configuration
+func newMigrateCommand(ctx context.Context) *cobra.Command {
+	command := &cobra.Command{
+		Use:               "migrate",
+		Short:             "Create or upgrade the database schema",
+		PersistentPreRunE: crawlerSetup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := repository.NewRepository(crawlerconfig.App.Database)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				err := repo.Close()
+				if err != nil {
+					logging.L().Error("unable to close DB connection", "error", err)
+				}
+			}()
+			return repo.Migrate(ctx)
+		},
+	}
+	crawlerconfig.AddFlags(command)
+	return command
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/common/pkg/natsconn"
+	crawlerconfig "github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/telemetry"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"sort"
+	"strings"
+)
+
+// newMonitorCommand creates the command that prints the telemetry reports published by the crawlers
+func newMonitorCommand(ctx context.Context) *cobra.Command {
+	command := &cobra.Command{
+		Use:               "monitor",
+		Short:             "Follow the crawlers telemetry",
+		PersistentPreRunE: crawlerSetup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nc, err := natsconn.Connect(crawlerconfig.App.Nats, 0, natsconn.Handlers{})
+			if err != nil {
+				return err
+			}
+			defer nc.Close()
+			_, err = nc.Subscribe(crawlerconfig.App.Monitoring.Topic, func(m *nats.Msg) {
+				var report telemetry.Report
+				err := json.Unmarshal(m.Data, &report)
+				if err != nil {
+					logging.L().Warn("unable to parse telemetry report", "error", err)
+					return
+				}
+				fmt.Printf("%s %s %s\n", report.At.Local().Format("15:04:05"), report.InstanceID, counters(report.Counters))
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Following %s, press Ctrl+C to exit\n", crawlerconfig.App.Monitoring.Topic)
+			<-ctx.Done()
+			return nil
+		},
+	}
+	crawlerconfig.AddFlags(command)
+	return command
+}
+
+// counters format the counters sorted by name
+func counters(values map[string]int64) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, values[name])
+	}
+	return strings.Join(parts, " ")
+}
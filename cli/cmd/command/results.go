@@ -0,0 +1,52 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	crawlerconfig "github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/repository"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+)
+
+// newResultsCommand creates the command that prints the stored results
+func newResultsCommand(ctx context.Context) *cobra.Command {
+	var requestID string
+	var limit int
+	var asJSON bool
+	command := &cobra.Command{
+		Use:               "results",
+		Short:             "Show the latest crawled URLs",
+		PersistentPreRunE: crawlerSetup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := repository.NewRepository(crawlerconfig.App.Database)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+			results, err := repo.Results(ctx, requestID, limit)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(results)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "REQUEST ID\tAPP\tRATING\tRATINGS\tSUCCESS\tERROR\tURL")
+			for _, r := range results {
+				fmt.Fprintf(w, "%s\t%s\t%.2f\t%d\t%t\t%s\t%s\n",
+					r.RequestID, r.AppName, r.Rating, r.RatingCount, r.Success, r.LastError, r.Url)
+			}
+			return w.Flush()
+		},
+	}
+	command.Flags().StringVar(&requestID, "request-id", "", "Show only the result of the request")
+	command.Flags().IntVarP(&limit, "limit", "n", 20, "Number of results")
+	command.Flags().BoolVar(&asJSON, "json", false, "Print the results as JSON")
+	crawlerconfig.AddFlags(command)
+	return command
+}
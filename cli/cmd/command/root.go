@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	crawlercmd "github.com/StevenRojas/natscrawler/crawler/cmd/command"
+	crawlerconfig "github.com/StevenRojas/natscrawler/crawler/config"
+	listenercmd "github.com/StevenRojas/natscrawler/listener/cmd/command"
+	listenerconfig "github.com/StevenRojas/natscrawler/listener/config"
+	readercmd "github.com/StevenRojas/natscrawler/reader/cmd/command"
+	readerconfig "github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// default configuration file of each service, used when the config flag is not set
+const (
+	readerConfigFile   = "config/reader.yaml"
+	listenerConfigFile = "config/listener.yaml"
+	crawlerConfigFile  = "config/crawler.yaml"
+)
+
+var (
+	// configFile configuration file of the service run by the command
+	configFile string
+	// logFile file where the logs are appended, stdout if empty
+	logFile string
+
+	// pre run functions loading the configuration of each service
+	readerSetup = setup("reader", &readerconfig.Filename, readerConfigFile, readerconfig.Setup,
+		func() logging.Config { return readerconfig.App.Log })
+	listenerSetup = setup("listener", &listenerconfig.Filename, listenerConfigFile, listenerconfig.Setup,
+		func() logging.Config { return listenerconfig.App.Log })
+	crawlerSetup = setup("crawler", &crawlerconfig.Filename, crawlerConfigFile, crawlerconfig.Setup,
+		func() logging.Config { return crawlerconfig.App.Log })
+)
+
+// NewRootCommand creates the natscrawler command, with a subcommand for each service and the operational commands
+func NewRootCommand(ctx context.Context) *cobra.Command {
+	rootCommand := &cobra.Command{
+		Use:          "natscrawler",
+		Short:        "Crawl the Roku channel store ratings using NATS",
+		SilenceUsage: true,
+	}
+	rootCommand.PersistentFlags().StringVarP(
+		&configFile,
+		"config",
+		"c",
+		"",
+		"Path to the config file, config/<service>.yaml by default",
+	)
+	rootCommand.PersistentFlags().StringVar(&logFile, "log-file", "", "Append the logs to the file instead of stdout")
+
+	submit := readercmd.NewCommand(ctx)
+	submit.Use = "submit"
+	submit.Short = "Send the URLs of a CSV file to the listener"
+	submit.PersistentPreRunE = readerSetup
+
+	listen := listenercmd.NewCommand(ctx)
+	listen.PersistentPreRunE = listenerSetup
+
+	crawl := crawlercmd.NewCommand(ctx)
+	crawl.Use = "crawl"
+	crawl.Short = "Collect the queued URLs and store the results"
+	crawl.PersistentPreRunE = crawlerSetup
+
+	rootCommand.AddCommand(
+		submit,
+		listen,
+		crawl,
+		newStatusCommand(ctx),
+		newResultsCommand(ctx),
+		newMigrateCommand(ctx),
+		newMonitorCommand(ctx),
+		newConfigCommand(),
+	)
+	return rootCommand
+}
+
+// setup returns a pre run function that loads the service configuration and sets the logger with the service
+// logging configuration
+func setup(service string, filename *string, defaultFile string, load func(*cobra.Command, []string) error,
+	logConf func() logging.Config) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		*filename = defaultFile
+		if configFile != "" {
+			*filename = configFile
+		}
+		err := load(cmd, args)
+		if err != nil {
+			return err
+		}
+		return setupLog(service, logConf())
+	}
+}
+
+// setupLog set the default logger writing to the log file, or stdout (stderr while the console display is shown)
+// if it is not set
+func setupLog(service string, conf logging.Config) error {
+	if logFile == "" {
+		return logging.Setup(logging.Output(conf), conf, "service", service)
+	}
+	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	return logging.Setup(file, conf, "service", service)
+}
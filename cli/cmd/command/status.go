@@ -0,0 +1,106 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	readerconfig "github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/StevenRojas/natscrawler/reader/pkg/sender"
+	"github.com/spf13/cobra"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// statusTimeout time allowed to each health check
+const statusTimeout = 5 * time.Second
+
+// newStatusCommand creates the command that checks the health of the listener and the crawlers
+func newStatusCommand(ctx context.Context) *cobra.Command {
+	var crawlers []string
+	command := &cobra.Command{
+		Use:               "status",
+		Short:             "Show the health of the listener and the crawlers",
+		PersistentPreRunE: readerSetup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SERVICE\tADDRESS\tSTATUS\tDETAILS")
+			healthy := listenerStatus(ctx, w)
+			for _, address := range crawlers {
+				healthy = crawlerStatus(ctx, w, address) && healthy
+			}
+			err := w.Flush()
+			if err != nil {
+				return err
+			}
+			if !healthy {
+				return fmt.Errorf("some services are not healthy")
+			}
+			return nil
+		},
+	}
+	command.Flags().StringSliceVar(&crawlers, "crawler", []string{"http://localhost:8080"}, "Crawler health server URLs")
+	readerconfig.AddFlags(command)
+	return command
+}
+
+// listenerStatus check the listener using the GRPC health service
+func listenerStatus(ctx context.Context, w *tabwriter.Writer) bool {
+	address := readerconfig.App.Grpc.ListenerAddress
+	conn, err := sender.Dial(ctx, readerconfig.App.Grpc)
+	if err != nil {
+		fmt.Fprintf(w, "listener\t%s\tUNREACHABLE\t%s\n", address, err.Error())
+		return false
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		fmt.Fprintf(w, "listener\t%s\tUNKNOWN\t%s\n", address, err.Error())
+		return false
+	}
+	fmt.Fprintf(w, "listener\t%s\t%s\t\n", address, response.Status)
+	return response.Status == healthpb.HealthCheckResponse_SERVING
+}
+
+// crawlerStatus check a crawler using its readiness probe
+func crawlerStatus(ctx context.Context, w *tabwriter.Writer, address string) bool {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(address, "/")+"/readyz", nil)
+	if err != nil {
+		fmt.Fprintf(w, "crawler\t%s\tUNKNOWN\t%s\n", address, err.Error())
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(w, "crawler\t%s\tUNREACHABLE\t%s\n", address, err.Error())
+		return false
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	names := make([]string, 0, len(body.Checks))
+	for name := range body.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	details := make([]string, len(names))
+	for i, name := range names {
+		details[i] = name + ": " + body.Checks[name]
+	}
+	status := "READY"
+	if resp.StatusCode != http.StatusOK {
+		status = "NOT READY"
+	}
+	fmt.Fprintf(w, "crawler\t%s\t%s\t%s\n", address, status, strings.Join(details, ", "))
+	return resp.StatusCode == http.StatusOK
+}
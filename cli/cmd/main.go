@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"github.com/StevenRojas/natscrawler/cli/cmd/command"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(signals)
+		cancel()
+	}()
+
+	go func() {
+		<-signals
+		logging.L().Info("propagating cancel signal (send it again to force the exit)")
+		cancel()
+		<-signals
+		logging.L().Warn("forced exit")
+		os.Exit(1)
+	}()
+
+	cmd := command.NewRootCommand(ctx)
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,60 @@
+module github.com/StevenRojas/natscrawler/cli
+
+go 1.18
+
+require (
+	github.com/StevenRojas/natscrawler/common v0.0.0
+	github.com/StevenRojas/natscrawler/crawler v0.0.0
+	github.com/StevenRojas/natscrawler/listener v0.0.0
+	github.com/StevenRojas/natscrawler/reader v0.0.0
+	github.com/nats-io/nats.go v1.13.1-0.20220308171302-2f2f6968e98d
+	github.com/spf13/cobra v1.4.0
+	google.golang.org/grpc v1.43.0
+)
+
+require (
+	github.com/StevenRojas/natscrawler/grpcapi v0.0.0 // indirect
+	github.com/chromedp/cdproto v0.0.0-20220321060548-7bc2623472b3 // indirect
+	github.com/chromedp/chromedp v0.8.0 // indirect
+	github.com/chromedp/sysutil v1.0.0 // indirect
+	github.com/fsnotify/fsnotify v1.5.1 // indirect
+	github.com/gobwas/httphead v0.1.0 // indirect
+	github.com/gobwas/pool v0.2.1 // indirect
+	github.com/gobwas/ws v1.1.0 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/uuid v1.1.2 // indirect
+	github.com/hashicorp/hcl v1.0.0 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/lib/pq v1.10.4 // indirect
+	github.com/magiconair/properties v1.8.5 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/mitchellh/mapstructure v1.4.3 // indirect
+	github.com/nats-io/nkeys v0.3.0 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/pelletier/go-toml v1.9.4 // indirect
+	github.com/spf13/afero v1.6.0 // indirect
+	github.com/spf13/cast v1.4.1 // indirect
+	github.com/spf13/jwalterweatherman v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/viper v1.10.1 // indirect
+	github.com/subosito/gotenv v1.2.0 // indirect
+	golang.org/x/crypto v0.0.0-20220112180741-5e0467b6c7ce // indirect
+	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2 // indirect
+	golang.org/x/sys v0.0.0-20220128215802-99c3d69c2c27 // indirect
+	golang.org/x/text v0.3.7 // indirect
+	golang.org/x/time v0.0.0-20211116232009-f0f3c7e86c11 // indirect
+	google.golang.org/genproto v0.0.0-20211208223120-3a66f561d7aa // indirect
+	google.golang.org/protobuf v1.28.0 // indirect
+	gopkg.in/ini.v1 v1.66.2 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+
+replace github.com/StevenRojas/natscrawler/grpcapi v0.0.0 => ../grpcapi
+
+replace github.com/StevenRojas/natscrawler/common v0.0.0 => ../common
+
+replace github.com/StevenRojas/natscrawler/crawler v0.0.0 => ../crawler
+
+replace github.com/StevenRojas/natscrawler/listener v0.0.0 => ../listener
+
+replace github.com/StevenRojas/natscrawler/reader v0.0.0 => ../reader
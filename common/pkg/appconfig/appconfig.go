@@ -0,0 +1,98 @@
+package appconfig
+
+import (
+	"errors"
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"reflect"
+	"strings"
+)
+
+// Load read the YAML configuration file into out, decoding durations (1s, 2h, etc.) and comma separated lists.
+// The precedence to override a configuration is: flag -> environment variable -> configuration field, the
+// environment variables are the keys with the prefix, capitalized and with the dots replaced by underscores
+//
+//	i.e.: nats.host => CT_L_NATS_HOST
+//
+// Every key of out can be set by its environment variable even if it is not in the file, and by a file whose path
+// is set by the environment variable with the _FILE suffix (i.e.: CT_C_DATABASE_PASSWORD_FILE=/run/secrets/db)
+// The configuration is validated if out implements Validator
+func Load(cmd *cobra.Command, filename string, environmentPrefix string, out interface{}) error {
+	v := viper.New()
+	v.SetConfigFile(filename)
+	v.SetConfigType("yaml")
+	v.AddConfigPath("./config")
+
+	v.SetEnvPrefix(environmentPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		_ = v.BindPFlag(flag.Name, flag)
+	})
+	for _, k := range keys(reflect.TypeOf(out), "") {
+		_ = v.BindEnv(k.name)
+		if flag := cmd.Flags().Lookup(k.name); flag != nil && flag.Changed {
+			continue
+		}
+		value, ok, err := fileValue(EnvName(environmentPrefix, k.name))
+		if err != nil {
+			return err
+		}
+		if ok {
+			v.Set(k.name, value)
+		}
+	}
+
+	err = v.Unmarshal(out, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+	if err != nil {
+		return err
+	}
+	if validator, ok := out.(Validator); ok {
+		return withEnv(validator.Validate(), environmentPrefix)
+	}
+	return nil
+}
+
+// EnvName returns the environment variable overriding the key
+func EnvName(environmentPrefix string, key string) string {
+	return environmentPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// withEnv set the environment variable of the validation errors
+func withEnv(err error, environmentPrefix string) error {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	for _, fieldErr := range errs {
+		// list items can not be overridden individually
+		if !strings.Contains(fieldErr.Key, "[") {
+			fieldErr.Env = EnvName(environmentPrefix, fieldErr.Key)
+		}
+	}
+	return errs
+}
+
+// Watch call onChange with the changed file each time the file changes, replacing the file (i.e.: Kubernetes
+// ConfigMap updates) is supported as well. The file is expected to be a YAML file
+func Watch(filename string, onChange func(name string)) {
+	v := viper.New()
+	v.SetConfigFile(filename)
+	v.SetConfigType("yaml")
+	v.OnConfigChange(func(event fsnotify.Event) {
+		onChange(event.Name)
+	})
+	v.WatchConfig()
+}
@@ -0,0 +1,281 @@
+package appconfig
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPrefix = "CT_T"
+
+type testConfig struct {
+	Nats     testNats      `mapstructure:"nats"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Password string        `mapstructure:"password" secret:"true"`
+}
+
+type testNats struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+func (c *testConfig) Validate() error {
+	var errs Errors
+	errs.Required("nats.host", c.Nats.Host)
+	errs.Range("nats.port", c.Nats.Port, 1, 65535)
+	errs.Positive("timeout", c.Timeout)
+	return errs.Err()
+}
+
+// load the configuration file content, with the flags set on the command
+func load(t *testing.T, content string, flags map[string]string) (testConfig, error) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var conf testConfig
+	cmd := &cobra.Command{}
+	AddFlags(cmd.Flags(), testPrefix, conf)
+	for name, value := range flags {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := Load(cmd, filename, testPrefix, &conf)
+	return conf, err
+}
+
+func TestLoad(t *testing.T) {
+	const file = "nats:\n  host: file\n  port: 4222\ntimeout: 1s\n"
+	tests := []struct {
+		name  string
+		env   map[string]string
+		flags map[string]string
+		want  testConfig
+	}{
+		{
+			name: "file",
+			want: testConfig{Nats: testNats{Host: "file", Port: 4222}, Timeout: time.Second},
+		},
+		{
+			name: "environment over file",
+			env:  map[string]string{"CT_T_NATS_HOST": "env", "CT_T_TIMEOUT": "2s"},
+			want: testConfig{Nats: testNats{Host: "env", Port: 4222}, Timeout: 2 * time.Second},
+		},
+		{
+			name:  "flags over environment and file",
+			env:   map[string]string{"CT_T_NATS_HOST": "env", "CT_T_NATS_PORT": "4223"},
+			flags: map[string]string{"nats.host": "flag", "timeout": "3s"},
+			want:  testConfig{Nats: testNats{Host: "flag", Port: 4223}, Timeout: 3 * time.Second},
+		},
+		{
+			name: "key set only by the environment",
+			env:  map[string]string{"CT_T_PASSWORD": "secret"},
+			want: testConfig{Nats: testNats{Host: "file", Port: 4222}, Timeout: time.Second, Password: "secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			got, err := load(t, file, tt.flags)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadValidate(t *testing.T) {
+	_, err := load(t, "nats:\n  port: 0\n", nil)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Load() error = %v, want validation errors", err)
+	}
+	want := Errors{
+		{Key: "nats.host", Env: "CT_T_NATS_HOST", Reason: "is required"},
+		{Key: "nats.port", Env: "CT_T_NATS_PORT", Reason: "must be between 1 and 65535, got 0"},
+		{Key: "timeout", Env: "CT_T_TIMEOUT", Reason: "must be greater than 0, got 0s"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Load() error = %v, want %v", errs, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors func(errs *Errors)
+		want   []string
+	}{
+		{name: "no errors", errors: func(errs *Errors) { errs.Required("host", "localhost") }},
+		{
+			name: "all errors collected",
+			errors: func(errs *Errors) {
+				errs.Required("host", " ")
+				errs.HostPort("address", "localhost")
+				errs.OneOf("format", "xml", "json", "text")
+				errs.URL("url", "https://example.com", "http")
+			},
+			want: []string{
+				"host: is required",
+				`address: must be an address like host:port, got "localhost"`,
+				`format: must be one of json, text, got "xml"`,
+				`url: must use the http scheme, got "https"`,
+			},
+		},
+		{
+			name: "nested errors prefixed",
+			errors: func(errs *Errors) {
+				var nested Errors
+				nested.Required("host", "")
+				nested.NotNegative("timeout", -time.Second)
+				errs.Merge("nats", nested.Err())
+				errs.Merge("tls", errors.New("unable to read the CA"))
+				errs.Merge("database", nil)
+			},
+			want: []string{
+				"nats.host: is required",
+				"nats.timeout: can not be negative, got -1s",
+				"tls: unable to read the CA",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs Errors
+			tt.errors(&errs)
+			err := errs.Err()
+			if (err != nil) != (len(tt.want) > 0) {
+				t.Fatalf("Err() = %v, want %d errors", err, len(tt.want))
+			}
+			var got []string
+			for _, fieldErr := range errs {
+				got = append(got, fieldErr.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	other := errors.New("other error")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "no error"},
+		{name: "not a validation error", err: other, want: other},
+		{
+			name: "keys named by their environment variables",
+			err: Errors{
+				{Key: "nats.host", Reason: "is required"},
+				{Key: "auth.api_keys[0].key", Reason: "is required"},
+			},
+			want: Errors{
+				{Key: "nats.host", Env: "CT_T_NATS_HOST", Reason: "is required"},
+				{Key: "auth.api_keys[0].key", Reason: "is required"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withEnv(tt.err, testPrefix)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileValue(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(secretFile, []byte("from-file\r\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const file = "nats:\n  host: file\n  port: 4222\ntimeout: 1s\npassword: in-file\n"
+	tests := []struct {
+		name    string
+		env     map[string]string
+		flags   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "configuration file", want: "in-file"},
+		{name: "read from the file, trailing new lines trimmed", env: map[string]string{"CT_T_PASSWORD_FILE": secretFile}, want: "from-file"},
+		{name: "empty file variable ignored", env: map[string]string{"CT_T_PASSWORD_FILE": ""}, want: "in-file"},
+		{
+			name: "environment variable over the file",
+			env:  map[string]string{"CT_T_PASSWORD": "from-env", "CT_T_PASSWORD_FILE": secretFile},
+			want: "from-env",
+		},
+		{
+			name:  "flag over the file",
+			env:   map[string]string{"CT_T_PASSWORD_FILE": secretFile},
+			flags: map[string]string{"password": "from-flag"},
+			want:  "from-flag",
+		},
+		{name: "missing file", env: map[string]string{"CT_T_PASSWORD_FILE": filepath.Join(dir, "missing")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			got, err := load(t, file, tt.flags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Password != tt.want {
+				t.Errorf("Load() password = %q, want %q", got.Password, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{
+			name:     "secret redacted",
+			password: "s3cret",
+			want:     "nats:\n  host: localhost\n  port: 4222\ntimeout: 1m30s\npassword: <redacted>\n",
+		},
+		{
+			name: "empty secret not redacted",
+			want: "nats:\n  host: localhost\n  port: 4222\ntimeout: 1m30s\npassword: \"\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &testConfig{
+				Nats:     testNats{Host: "localhost", Port: 4222},
+				Timeout:  90 * time.Second,
+				Password: tt.password,
+			}
+			var out strings.Builder
+			if err := Print(&out, conf); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Print() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
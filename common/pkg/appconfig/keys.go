@@ -0,0 +1,111 @@
+package appconfig
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// fileSuffix suffix of the environment variables with the path of a file holding the value, i.e.: mounted secrets
+const fileSuffix = "_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// key configuration key that can be overridden by a flag or an environment variable
+type key struct {
+	name string
+	kind reflect.Type
+}
+
+// keys returns the configuration keys of the struct type that hold a single value or a list of strings,
+// the lists of structs can only be set in the configuration file
+func keys(t reflect.Type, prefix string) []key {
+	var out []key
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, options := parseTag(field)
+		if options == "squash" {
+			out = append(out, keys(field.Type, prefix)...)
+			continue
+		}
+		name = prefix + name
+		switch {
+		case field.Type == durationType:
+			out = append(out, key{name: name, kind: field.Type})
+		case field.Type.Kind() == reflect.Struct:
+			out = append(out, keys(field.Type, name+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.String:
+		case field.Type.Kind() == reflect.Map:
+		default:
+			out = append(out, key{name: name, kind: field.Type})
+		}
+	}
+	return out
+}
+
+// AddFlags add a flag for each key of the configuration, named as the key (i.e.: --nats.host). The flags are
+// bound by Load, overriding the environment variables and the configuration file
+func AddFlags(flags *pflag.FlagSet, environmentPrefix string, config interface{}) {
+	for _, k := range keys(reflect.TypeOf(config), "") {
+		if flags.Lookup(k.name) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("Override %s, also set by %s", k.name, EnvName(environmentPrefix, k.name))
+		switch {
+		case k.kind == durationType:
+			flags.Duration(k.name, 0, usage)
+		case k.kind.Kind() == reflect.Bool:
+			flags.Bool(k.name, false, usage)
+		case k.kind.Kind() == reflect.Int:
+			flags.Int(k.name, 0, usage)
+		case k.kind.Kind() == reflect.Int64:
+			flags.Int64(k.name, 0, usage)
+		case k.kind.Kind() == reflect.Float64:
+			flags.Float64(k.name, 0, usage)
+		case k.kind.Kind() == reflect.Slice:
+			flags.StringSlice(k.name, nil, usage)
+		default:
+			flags.String(k.name, "", usage)
+		}
+	}
+}
+
+// fileValue returns the content of the file set by the environment variable with the _FILE suffix, without the
+// trailing new lines. It is not read if the environment variable itself is set
+func fileValue(env string) (string, bool, error) {
+	if _, ok := os.LookupEnv(env); ok {
+		return "", false, nil
+	}
+	filename, ok := os.LookupEnv(env + fileSuffix)
+	if !ok || filename == "" {
+		return "", false, nil
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to read %s%s: %w", env, fileSuffix, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+// parseTag returns the mapstructure name and options of the field, the lower case field name is used if not tagged
+func parseTag(field reflect.StructField) (string, string) {
+	tag := field.Tag.Get("mapstructure")
+	name, options := tag, ""
+	if i := strings.Index(tag, ","); i >= 0 {
+		name, options = tag[:i], tag[i+1:]
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, options
+}
@@ -0,0 +1,81 @@
+package appconfig
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"reflect"
+	"time"
+)
+
+// redacted value printed instead of the secrets
+const redacted = "<redacted>"
+
+// Print write the configuration as YAML, with the values of the fields tagged with secret:"true" redacted
+func Print(w io.Writer, config interface{}) error {
+	out, err := yaml.Marshal(Redact(config))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// Redact returns the configuration as a map keyed by the mapstructure names, the durations are formatted
+// (1s, 2h, etc.) and the non empty values of the fields tagged with secret:"true" are redacted
+func Redact(config interface{}) interface{} {
+	return redact(reflect.ValueOf(config))
+}
+
+func redact(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem())
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		out := yaml.MapSlice{}
+		redactStruct(v, &out)
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = redact(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		out := yaml.MapSlice{}
+		for _, key := range v.MapKeys() {
+			out = append(out, yaml.MapItem{Key: fmt.Sprint(key.Interface()), Value: redact(v.MapIndex(key))})
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// redactStruct add the exported fields to out, the squashed structs fields are added at the same level
+func redactStruct(v reflect.Value, out *yaml.MapSlice) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, options := parseTag(field)
+		if options == "squash" {
+			redactStruct(v.Field(i), out)
+			continue
+		}
+		value := redact(v.Field(i))
+		if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+			value = redacted
+		}
+		*out = append(*out, yaml.MapItem{Key: name, Value: value})
+	}
+}
@@ -0,0 +1,142 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validator configuration validated once it is loaded
+type Validator interface {
+	Validate() error
+}
+
+// FieldError invalid configuration key
+type FieldError struct {
+	Key string
+	// Env environment variable overriding the key, set by Load
+	Env    string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	if e.Env != "" {
+		return fmt.Sprintf("%s (%s): %s", e.Key, e.Env, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Key, e.Reason)
+}
+
+// Errors aggregated validation errors, so all of them are reported at once
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid configuration:\n  " + strings.Join(messages, "\n  ")
+}
+
+// Add an error of the key
+func (e *Errors) Add(key string, format string, args ...interface{}) {
+	*e = append(*e, &FieldError{Key: key, Reason: fmt.Sprintf(format, args...)})
+}
+
+// Merge add the errors of a nested configuration, prefixing their keys with the nested key
+func (e *Errors) Merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var nested Errors
+	if !errors.As(err, &nested) {
+		*e = append(*e, &FieldError{Key: prefix, Reason: err.Error()})
+		return
+	}
+	for _, fieldErr := range nested {
+		*e = append(*e, &FieldError{Key: prefix + "." + fieldErr.Key, Reason: fieldErr.Reason})
+	}
+}
+
+// Err returns the errors, or nil if there are none
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Required add an error if the value is empty
+func (e *Errors) Required(key string, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(key, "is required")
+	}
+}
+
+// Positive add an error if the duration is not greater than 0
+func (e *Errors) Positive(key string, value time.Duration) {
+	if value <= 0 {
+		e.Add(key, "must be greater than 0, got %s", value)
+	}
+}
+
+// NotNegative add an error if the duration is negative
+func (e *Errors) NotNegative(key string, value time.Duration) {
+	if value < 0 {
+		e.Add(key, "can not be negative, got %s", value)
+	}
+}
+
+// Range add an error if the value is not between min and max, both included
+func (e *Errors) Range(key string, value, min, max int) {
+	if value < min || value > max {
+		e.Add(key, "must be between %d and %d, got %d", min, max, value)
+	}
+}
+
+// OneOf add an error if the value is not one of the allowed ones
+func (e *Errors) OneOf(key string, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.Add(key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// HostPort add an error if the value is not an address like host:port, the host can be empty to listen on all
+// the interfaces
+func (e *Errors) HostPort(key string, value string) {
+	if value == "" {
+		e.Add(key, "is required")
+		return
+	}
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		e.Add(key, "must be an address like host:port, got %q", value)
+		return
+	}
+	if _, err = net.LookupPort("tcp", port); err != nil {
+		e.Add(key, "has an invalid port %q", port)
+	}
+}
+
+// URL add an error if the value is not an absolute URL with one of the schemes, any scheme is allowed if none is set
+func (e *Errors) URL(key string, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		e.Add(key, "must be an absolute URL, got %q", value)
+		return
+	}
+	if len(schemes) == 0 {
+		return
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	e.Add(key, "must use the %s scheme, got %q", strings.Join(schemes, " or "), u.Scheme)
+}
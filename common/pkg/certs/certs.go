@@ -0,0 +1,204 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoCertificate the store has no key pair to serve
+	ErrNoCertificate = errors.New("no certificate configured")
+	// ErrNoServerName the client has no server name to verify the server certificate with
+	ErrNoServerName = errors.New("no server name to verify the server certificate")
+)
+
+// Store certificates loaded from files. The files are checked for changes at most once per reload interval
+// during the TLS handshakes, so renewed certificates are used without a restart
+type Store interface {
+	// ServerConfig returns the TLS configuration of a server, client certificates signed by the CA are
+	// required if requireClientCert is set (mTLS)
+	ServerConfig(requireClientCert bool) *tls.Config
+	// ClientConfig returns the TLS configuration of a client. The server certificate is verified with the
+	// CA, or with the system roots if there is no CA file, for the server name of each connection, or the
+	// default name (a host name or an IP address) if the connection has none
+	ClientConfig(serverName string, defaultName string) *tls.Config
+}
+
+type store struct {
+	certFile  string
+	keyFile   string
+	caFile    string
+	interval  time.Duration
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+// NewStore load the key pair and the CA bundle, any of them can be empty
+func NewStore(certFile string, keyFile string, caFile string, reloadInterval time.Duration) (Store, error) {
+	s := &store{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		interval: reloadInterval,
+		modTimes: map[string]time.Time{},
+	}
+	err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ServerConfig returns a server TLS configuration
+func (s *store) ServerConfig(requireClientCert bool) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := s.current()
+			if cert == nil {
+				return nil, ErrNoCertificate
+			}
+			conf := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2"},
+			}
+			if requireClientCert {
+				conf.ClientAuth = tls.RequireAndVerifyClientCert
+				conf.ClientCAs = pool
+			}
+			return conf, nil
+		},
+	}
+}
+
+// ClientConfig returns a client TLS configuration. The default verification is replaced by one using the
+// current CA bundle, since the RootCAs of the configuration can not be reloaded. The server name can be empty so
+// the client sets the host of each connection (i.e.: NATS servers of a cluster). The certificate is verified for
+// the server name of the connection state, or the default name if it is empty, as it is for IP addresses. The
+// handshake fails if there is no name to verify
+func (s *store) ClientConfig(serverName string, defaultName string) *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := s.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+		VerifyConnection: func(state tls.ConnectionState) error {
+			name := state.ServerName
+			if name == "" {
+				name = defaultName
+			}
+			if name == "" {
+				return ErrNoServerName
+			}
+			_, pool := s.current()
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server did not send a certificate")
+			}
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				DNSName:       name,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range state.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
+
+// current returns the key pair and CA pool, reloading them if the files changed. The previous certificates are
+// kept if the new ones could not be loaded, i.e.: the files are being written
+func (s *store) current() (*tls.Certificate, *x509.CertPool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.checkedAt) >= s.interval && s.changed() {
+		err := s.load()
+		if err != nil {
+			logging.L().Error("unable to reload certificates", "error", err)
+		} else {
+			logging.L().Info("certificates reloaded", "cert_file", s.certFile)
+		}
+	}
+	return s.cert, s.pool
+}
+
+// changed returns true if the modification time of any file changed since they were loaded
+func (s *store) changed() bool {
+	s.checkedAt = time.Now()
+	for _, file := range s.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(s.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// load read the files, the stored certificates are only replaced if all of them are valid
+func (s *store) load() error {
+	modTimes := map[string]time.Time{}
+	for _, file := range s.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if s.certFile != "" || s.keyFile != "" {
+		pair, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load key pair: %w", err)
+		}
+		cert = &pair
+	}
+	var pool *x509.CertPool
+	if s.caFile != "" {
+		pem, err := os.ReadFile(s.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found at %s", s.caFile)
+		}
+	}
+
+	s.cert = cert
+	s.pool = pool
+	s.modTimes = modTimes
+	s.checkedAt = time.Now()
+	return nil
+}
+
+func (s *store) files() []string {
+	var files []string
+	for _, file := range []string{s.certFile, s.keyFile, s.caFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
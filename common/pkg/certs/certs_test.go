@@ -0,0 +1,126 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCerts write a CA and a server certificate for the listener host and 127.0.0.1, returning the files
+func writeCerts(t *testing.T) (string, string, string) {
+	dir := t.TempDir()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "listener"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"listener"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	write := func(name string, blockType string, data []byte) string {
+		file := filepath.Join(dir, name)
+		err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	return write("server.crt", "CERTIFICATE", der), write("server.key", "EC PRIVATE KEY", keyDER),
+		write("ca.crt", "CERTIFICATE", caDER)
+}
+
+func TestClientConfigServerName(t *testing.T) {
+	certFile, keyFile, caFile := writeCerts(t)
+	server, err := NewStore(certFile, keyFile, "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewStore("", "", caFile, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", server.ServerConfig(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		name string
+		// serverName configured server name, the client sets the host of each connection if it is empty
+		serverName  string
+		defaultName string
+		wantErr     bool
+	}{
+		{name: "IP address in the certificate", defaultName: "127.0.0.1"},
+		{name: "host name in the certificate", serverName: "listener"},
+		{name: "host name of the connection over the default one", serverName: "listener", defaultName: "other"},
+		{name: "IP address not in the certificate", defaultName: "10.0.0.5", wantErr: true},
+		{name: "host name not in the certificate", serverName: "other", defaultName: "listener", wantErr: true},
+		{name: "no server name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := client.ClientConfig(tt.serverName, tt.defaultName)
+			if conf.ServerName == "" {
+				// the client sets the host it dials, an IP address is not sent so the connection state has no name
+				conf.ServerName = "127.0.0.1"
+			}
+			conn, err := tls.Dial("tcp", ln.Addr().String(), conf)
+			if err == nil {
+				conn.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.serverName == "" && tt.defaultName == "" && !errors.Is(err, ErrNoServerName) {
+				t.Errorf("Dial() error = %v, want %v", err, ErrNoServerName)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeJSON encode the key value pairs as a JSON object line, keeping their order
+func encodeJSON(keyvals []interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(keyString(keyvals[i]))
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(fieldValue(pairValue(keyvals, i)))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(pairValue(keyvals, i)))
+		}
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// encodeLogfmt encode the key value pairs as a logfmt line: key=value key="quoted value"
+func encodeLogfmt(keyvals []interface{}) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(strings.ReplaceAll(keyString(keyvals[i]), " ", "_"))
+		buf.WriteByte('=')
+		value := fmt.Sprint(fieldValue(pairValue(keyvals, i)))
+		if value == "" || strings.ContainsAny(value, " =\"\t\r\n\\") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(value)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// pairValue returns the value of the key at i, a missing value is reported instead of dropping the key
+func pairValue(keyvals []interface{}, i int) interface{} {
+	if i+1 < len(keyvals) {
+		return keyvals[i+1]
+	}
+	return "(MISSING)"
+}
+
+func keyString(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// fieldValue returns the value to encode, errors, durations and stringers are encoded as text
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,241 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level log level, the records below the configured level are discarded
+type Level int
+
+// Log levels
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel returns the level of the name: debug, info, warn or error. Info is used if it is empty
+func ParseLevel(name string) (Level, error) {
+	if name == "" {
+		return LevelInfo, nil
+	}
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// Job ID carriers, the job ID identifies the URLs submitted together (i.e.: a reader run) to correlate their logs
+const (
+	// JobIDMetadata GRPC metadata key sent by the reader to the listener
+	JobIDMetadata = "job-id"
+	// JobIDHeader NATS header sent by the listener to the crawlers
+	JobIDHeader = "Job-Id"
+)
+
+// Output formats
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+// Config logging configuration
+type Config struct {
+	// Level minimum level logged: debug, info (default), warn or error
+	Level string `mapstructure:"level"`
+	// Format json (default) or logfmt
+	Format string `mapstructure:"format"`
+	// Console show the interactive console display instead of logging the progress, only if the output is a terminal
+	Console bool `mapstructure:"console"`
+}
+
+// Validate the logging configuration
+func (c Config) Validate() error {
+	var errs appconfig.Errors
+	if _, err := ParseLevel(c.Level); err != nil {
+		errs.Add("level", "must be one of debug, info, warn, error, got %q", c.Level)
+	}
+	if c.Format != "" {
+		errs.OneOf("format", c.Format, FormatJSON, FormatLogfmt)
+	}
+	return errs.Err()
+}
+
+// Logger structured logger, the fields are key value pairs: logger.Info("URL stored", "request_id", id)
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+	// With returns a logger adding the fields to every record
+	With(keyvals ...interface{}) Logger
+	// Enabled reports whether the records of the level are logged
+	Enabled(level Level) bool
+}
+
+// output destination shared by a logger and the loggers created by With
+type output struct {
+	mu     sync.Mutex
+	w      io.Writer
+	level  Level
+	format string
+}
+
+type logger struct {
+	out    *output
+	fields []interface{}
+}
+
+// New returns a logger writing records of the configured level or above to w
+func New(w io.Writer, conf Config, keyvals ...interface{}) (Logger, error) {
+	level, err := ParseLevel(conf.Level)
+	if err != nil {
+		return nil, err
+	}
+	format := conf.Format
+	if format != FormatLogfmt {
+		format = FormatJSON
+	}
+	return &logger{
+		out:    &output{w: w, level: level, format: format},
+		fields: keyvals,
+	}, nil
+}
+
+func (l *logger) Debug(msg string, keyvals ...interface{}) {
+	l.log(LevelDebug, msg, keyvals)
+}
+
+func (l *logger) Info(msg string, keyvals ...interface{}) {
+	l.log(LevelInfo, msg, keyvals)
+}
+
+func (l *logger) Warn(msg string, keyvals ...interface{}) {
+	l.log(LevelWarn, msg, keyvals)
+}
+
+func (l *logger) Error(msg string, keyvals ...interface{}) {
+	l.log(LevelError, msg, keyvals)
+}
+
+func (l *logger) With(keyvals ...interface{}) Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(keyvals))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+	return &logger{out: l.out, fields: fields}
+}
+
+func (l *logger) Enabled(level Level) bool {
+	return level >= l.out.level
+}
+
+func (l *logger) log(level Level, msg string, keyvals []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	fields := make([]interface{}, 0, 6+len(l.fields)+len(keyvals))
+	fields = append(fields, "time", time.Now().UTC().Format(time.RFC3339Nano), "level", level.String(), "msg", msg)
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+	var line []byte
+	if l.out.format == FormatJSON {
+		line = encodeJSON(fields)
+	} else {
+		line = encodeLogfmt(fields)
+	}
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	_, _ = l.out.w.Write(line)
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger Logger = &logger{out: &output{w: os.Stderr, level: LevelInfo, format: FormatLogfmt}}
+)
+
+// Setup set the default logger writing to w, the instance ID (host name) is added to every record. The standard
+// log package output is redirected to the default logger as info records, so its messages are structured as well
+func Setup(w io.Writer, conf Config, keyvals ...interface{}) error {
+	instanceID, _ := os.Hostname()
+	l, err := New(w, conf, append([]interface{}{"instance_id", instanceID}, keyvals...)...)
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defaultLogger = l
+	defaultMu.Unlock()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(stdWriter{})
+	return nil
+}
+
+// L returns the default logger
+func L() Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// stdWriter writes the standard log package messages to the default logger
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	L().Info(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the logger, i.e.: a logger with the fields of the processed request
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger of the context, or the default logger if there is none
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return L()
+}
+
+// Output returns the log destination, stderr while the console display is shown on stdout, so the records do not
+// break the display and can be redirected apart, otherwise stdout
+func Output(conf Config) io.Writer {
+	if conf.Console && IsTerminal(os.Stdout) {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// IsTerminal reports whether the file is a terminal, the interactive console displays are only shown on terminals
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
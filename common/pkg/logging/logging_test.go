@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name       string
+		keyvals    []interface{}
+		wantJSON   string
+		wantLogfmt string
+	}{
+		{
+			name:       "plain values",
+			keyvals:    []interface{}{"msg", "stored", "count", 3, "ok", true},
+			wantJSON:   `{"msg":"stored","count":3,"ok":true}`,
+			wantLogfmt: `msg=stored count=3 ok=true`,
+		},
+		{
+			name:       "spaces",
+			keyvals:    []interface{}{"msg", "URL stored", "tab", "a\tb"},
+			wantJSON:   `{"msg":"URL stored","tab":"a\tb"}`,
+			wantLogfmt: `msg="URL stored" tab="a\tb"`,
+		},
+		{
+			name:       "quotes and new lines",
+			keyvals:    []interface{}{"msg", `say "hi"`, "stack", "line 1\nline 2", "path", `C:\tmp`},
+			wantJSON:   `{"msg":"say \"hi\"","stack":"line 1\nline 2","path":"C:\\tmp"}`,
+			wantLogfmt: `msg="say \"hi\"" stack="line 1\nline 2" path="C:\\tmp"`,
+		},
+		{
+			name:       "empty value and equal sign",
+			keyvals:    []interface{}{"empty", "", "query", "a=b"},
+			wantJSON:   `{"empty":"","query":"a=b"}`,
+			wantLogfmt: `empty="" query="a=b"`,
+		},
+		{
+			name:       "key with spaces and not a string",
+			keyvals:    []interface{}{"request id", "1", 2, "two"},
+			wantJSON:   `{"request id":"1","2":"two"}`,
+			wantLogfmt: `request_id=1 2=two`,
+		},
+		{
+			name:       "odd key value count",
+			keyvals:    []interface{}{"msg", "stored", "request_id"},
+			wantJSON:   `{"msg":"stored","request_id":"(MISSING)"}`,
+			wantLogfmt: `msg=stored request_id=(MISSING)`,
+		},
+		{
+			name: "errors, durations, times and nil",
+			keyvals: []interface{}{"error", errors.New("connection refused"), "took", 1500 * time.Millisecond,
+				"at", time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC), "cause", nil},
+			wantJSON:   `{"error":"connection refused","took":"1.5s","at":"2022-04-01T10:00:00Z","cause":null}`,
+			wantLogfmt: `error="connection refused" took=1.5s at=2022-04-01T10:00:00Z cause=<nil>`,
+		},
+		{
+			name:       "value not encodable as JSON",
+			keyvals:    []interface{}{"rating", math.Inf(1)},
+			wantJSON:   `{"rating":"+Inf"}`,
+			wantLogfmt: `rating=+Inf`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotJSON := string(encodeJSON(tt.keyvals))
+			gotLogfmt := string(encodeLogfmt(tt.keyvals))
+			if gotJSON != tt.wantJSON+"\n" {
+				t.Errorf("encodeJSON() = %q, want %q", gotJSON, tt.wantJSON)
+			}
+			if gotLogfmt != tt.wantLogfmt+"\n" {
+				t.Errorf("encodeLogfmt() = %q, want %q", gotLogfmt, tt.wantLogfmt)
+			}
+		})
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	tests := []struct {
+		level  string
+		format string
+		// want messages logged, in order
+		want []string
+	}{
+		{level: "debug", format: FormatJSON, want: []string{`"msg":"debug"`, `"msg":"info"`, `"msg":"warn"`, `"msg":"error"`}},
+		{level: "", format: FormatJSON, want: []string{`"msg":"info"`, `"msg":"warn"`, `"msg":"error"`}},
+		{level: "WARN", format: FormatLogfmt, want: []string{`level=warn msg=warn`, `level=error msg=error`}},
+		{level: "error", format: FormatLogfmt, want: []string{`level=error msg=error`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level+" "+tt.format, func(t *testing.T) {
+			var out strings.Builder
+			l, err := New(&out, Config{Level: tt.level, Format: tt.format}, "service", "crawler")
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			l = l.With("collector", 1)
+			l.Debug("debug")
+			l.Info("info")
+			l.Warn("warn")
+			l.Error("error", "request_id", "1")
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("logged %d lines, want %d: %q", len(lines), len(tt.want), lines)
+			}
+			for i, line := range lines {
+				if !strings.Contains(line, tt.want[i]) {
+					t.Errorf("line %d = %q, want it to contain %q", i, line, tt.want[i])
+				}
+				// the logger and With fields are added to every record
+				if !strings.Contains(line, "crawler") || !strings.Contains(line, "collector") {
+					t.Errorf("line %d = %q, want the logger fields", i, line)
+				}
+			}
+		})
+	}
+}
+
+func TestNewUnknownLevel(t *testing.T) {
+	if _, err := New(&strings.Builder{}, Config{Level: "verbose"}); err == nil {
+		t.Error("New() error = nil, want an error for an unknown level")
+	}
+}
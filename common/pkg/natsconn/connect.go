@@ -0,0 +1,199 @@
+package natsconn
+
+import (
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/nats-io/nats.go"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config NATS server configuration
+type Config struct {
+	Host string `mapstructure:"host"`
+	// Servers cluster server URLs, used along with the host
+	Servers              []string      `mapstructure:"servers"`
+	AllowReconnect       bool          `mapstructure:"allow_reconnect"`
+	MaxReconnectAttempts int           `mapstructure:"max_reconnect_attempts"`
+	ReconnectWait        time.Duration `mapstructure:"reconnect_wait"`
+	Timeout              time.Duration `mapstructure:"timeout"`
+	Auth                 Auth          `mapstructure:"auth"`
+	TLS                  TLS           `mapstructure:"tls"`
+}
+
+// Queue NATS queue configuration
+type Queue struct {
+	Topic string `mapstructure:"topic"`
+	Group string `mapstructure:"group"`
+	// CancelTopic topic where the request cancellations are broadcast to the crawlers
+	CancelTopic string `mapstructure:"cancel_topic"`
+}
+
+// Monitoring configuration
+type Monitoring struct {
+	Topic    string        `mapstructure:"topic"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Handlers connection status callbacks, called after the event is logged. Any of them can be nil
+type Handlers struct {
+	Disconnected func(nc *nats.Conn, err error)
+	Reconnected  func(nc *nats.Conn)
+	Closed       func(nc *nats.Conn)
+}
+
+// Validate the server configuration
+func (c Config) Validate() error {
+	var errs appconfig.Errors
+	if c.Host == "" && len(c.Servers) == 0 {
+		errs.Add("host", "host or servers is required")
+	}
+	if c.Host != "" {
+		validateServer(&errs, "host", c.Host)
+	}
+	for i, server := range c.Servers {
+		validateServer(&errs, fmt.Sprintf("servers[%d]", i), server)
+	}
+	errs.Positive("timeout", c.Timeout)
+	if c.AllowReconnect {
+		errs.Positive("reconnect_wait", c.ReconnectWait)
+		if c.MaxReconnectAttempts < -1 {
+			errs.Add("max_reconnect_attempts", "must be -1 (unlimited) or greater, got %d", c.MaxReconnectAttempts)
+		}
+	}
+	if _, err := Options(c.Auth, TLS{}, ""); err == ErrMultipleAuth {
+		errs.Add("auth", err.Error())
+	}
+	if c.TLS.CertFile != "" && c.TLS.KeyFile == "" {
+		errs.Add("tls.key_file", "is required with the cert_file")
+	}
+	return errs.Err()
+}
+
+// Validate the queue configuration
+func (q Queue) Validate() error {
+	var errs appconfig.Errors
+	errs.Required("topic", q.Topic)
+	if q.CancelTopic != "" && q.CancelTopic == q.Topic {
+		errs.Add("cancel_topic", "must be different than the topic")
+	}
+	return errs.Err()
+}
+
+// Validate the monitoring configuration, it is disabled if the topic is empty
+func (m Monitoring) Validate() error {
+	var errs appconfig.Errors
+	if m.Topic != "" {
+		errs.Positive("interval", m.Interval)
+	}
+	return errs.Err()
+}
+
+// validateServer add an error if the server is not a NATS URL or a host:port address
+func validateServer(errs *appconfig.Errors, key string, server string) {
+	if strings.Contains(server, "://") {
+		errs.URL(key, server, "nats", "tls", "ws", "wss")
+		return
+	}
+	errs.HostPort(key, server)
+}
+
+// serverHost returns the host of the first server, used to verify the server certificates of the connections
+// without a host name
+func (c Config) serverHost() string {
+	server := strings.Split(c.Host, ",")[0]
+	if server == "" && len(c.Servers) > 0 {
+		server = c.Servers[0]
+	}
+	server = strings.TrimSpace(server)
+	if strings.Contains(server, "://") {
+		parsed, err := url.Parse(server)
+		if err != nil {
+			return ""
+		}
+		return parsed.Hostname()
+	}
+	host, _, err := net.SplitHostPort(server)
+	if err != nil {
+		return server
+	}
+	return host
+}
+
+// Connect connect to the NATS server, logging the connection status changes and the asynchronous errors.
+// The drain timeout limits the time allowed to Drain the connection
+func Connect(conf Config, drainTimeout time.Duration, handlers Handlers) (*nats.Conn, error) {
+	opts := nats.Options{
+		Url:            conf.Host,
+		Servers:        conf.Servers,
+		AllowReconnect: conf.AllowReconnect,
+		MaxReconnect:   conf.MaxReconnectAttempts,
+		ReconnectWait:  conf.ReconnectWait,
+		Timeout:        conf.Timeout,
+		DrainTimeout:   drainTimeout,
+		DisconnectedErrCB: func(nc *nats.Conn, err error) {
+			// the error is nil when the connection is closed
+			if err != nil {
+				logging.L().Warn("NATS disconnected", "error", err)
+			}
+			if handlers.Disconnected != nil {
+				handlers.Disconnected(nc, err)
+			}
+		},
+		ReconnectedCB: func(nc *nats.Conn) {
+			logging.L().Info("NATS reconnected", "url", nc.ConnectedUrl())
+			if handlers.Reconnected != nil {
+				handlers.Reconnected(nc)
+			}
+		},
+		ClosedCB: func(nc *nats.Conn) {
+			logging.L().Info("NATS connection closed")
+			if handlers.Closed != nil {
+				handlers.Closed(nc)
+			}
+		},
+		AsyncErrorCB: func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			if sub != nil {
+				logging.L().Error("NATS error", "subject", sub.Subject, "error", err)
+				return
+			}
+			logging.L().Error("NATS error", "error", err)
+		},
+	}
+	secure, err := Options(conf.Auth, conf.TLS, conf.serverHost())
+	if err != nil {
+		return nil, err
+	}
+	err = Apply(&opts, secure)
+	if err != nil {
+		return nil, err
+	}
+
+	nc, err := opts.Connect()
+	if err != nil {
+		return nil, err
+	}
+	logging.L().Info("connected to NATS", "url", nc.ConnectedUrl())
+	return nc, nil
+}
+
+// Drain drain the subscriptions and flush the pending messages, waiting until the connection is closed
+func Drain(nc *nats.Conn) error {
+	closed := make(chan struct{})
+	previous := nc.Opts.ClosedCB
+	nc.SetClosedHandler(func(nc *nats.Conn) {
+		if previous != nil {
+			previous(nc)
+		}
+		close(closed)
+	})
+	err := nc.Drain()
+	if err != nil {
+		return err
+	}
+	<-closed
+	return nil
+}
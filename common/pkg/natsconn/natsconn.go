@@ -0,0 +1,100 @@
+package natsconn
+
+import (
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/certs"
+	"github.com/nats-io/nats.go"
+	"time"
+)
+
+// certsReloadInterval time between checks of the TLS files for changes
+const certsReloadInterval = time.Minute
+
+// ClientHeader NATS header with the client that queued a URL or asked for its cancellation, a request can only be
+// cancelled by the client that queued it
+const ClientHeader = "Client"
+
+// SentAtHeader NATS header with the time the listener published a URL or a cancellation (RFC 3339), a cancellation
+// only applies to the URLs of the request published before it
+const SentAtHeader = "Sent-At"
+
+// ErrMultipleAuth more than one authentication method is configured
+var ErrMultipleAuth = errors.New("only one of user, token, nkey_seed_file or credentials_file can be set")
+
+// Auth NATS credentials, only one method is expected
+type Auth struct {
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password" secret:"true"`
+	Token    string `mapstructure:"token" secret:"true"`
+	// NKeySeedFile file with the NKey seed used to sign the server nonce
+	NKeySeedFile string `mapstructure:"nkey_seed_file"`
+	// CredentialsFile .creds file with the user JWT and NKey seed (decentralized auth)
+	CredentialsFile string `mapstructure:"credentials_file"`
+}
+
+// TLS NATS TLS configuration, the system roots are used if there is no CA file
+type TLS struct {
+	Enabled bool   `mapstructure:"enabled"`
+	CAFile  string `mapstructure:"ca_file"`
+	// CertFile and KeyFile client certificate, required if the server verifies the clients
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ServerName name verified in the server certificates, the host of each server if it is empty
+	ServerName string `mapstructure:"server_name"`
+}
+
+// Options returns the NATS options setting the credentials and TLS. The server certificates are verified for the
+// configured server name, or the host of each server. The default host is verified for the servers without a host
+// name to send, i.e.: IP addresses
+func Options(auth Auth, tlsConf TLS, defaultHost string) ([]nats.Option, error) {
+	var opts []nats.Option
+	methods := 0
+	if auth.User != "" {
+		methods++
+		opts = append(opts, nats.UserInfo(auth.User, auth.Password))
+	}
+	if auth.Token != "" {
+		methods++
+		opts = append(opts, nats.Token(auth.Token))
+	}
+	if auth.NKeySeedFile != "" {
+		methods++
+		opt, err := nats.NkeyOptionFromSeed(auth.NKeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load NATS nkey seed: %w", err)
+		}
+		opts = append(opts, opt)
+	}
+	if auth.CredentialsFile != "" {
+		methods++
+		opts = append(opts, nats.UserCredentials(auth.CredentialsFile))
+	}
+	if methods > 1 {
+		return nil, ErrMultipleAuth
+	}
+
+	if tlsConf.Enabled {
+		store, err := certs.NewStore(tlsConf.CertFile, tlsConf.KeyFile, tlsConf.CAFile, certsReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load NATS certificates: %w", err)
+		}
+		defaultName := tlsConf.ServerName
+		if defaultName == "" {
+			defaultName = defaultHost
+		}
+		opts = append(opts, nats.Secure(store.ClientConfig(tlsConf.ServerName, defaultName)))
+	}
+	return opts, nil
+}
+
+// Apply set the options to the NATS connection options
+func Apply(natsOpts *nats.Options, opts []nats.Option) error {
+	for _, opt := range opts {
+		err := opt(natsOpts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package rokuurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Host canonical channel store host
+const Host = "channelstore.roku.com"
+
+var (
+	// ErrInvalidURL the value is not an absolute URL
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrUnsupportedHost the URL is not for the Roku channel store
+	ErrUnsupportedHost = errors.New("unsupported host")
+	// ErrUnsupportedPath the URL path is not a channel details page
+	ErrUnsupportedPath = errors.New("unsupported path")
+	// ErrInvalidChannelID the channel ID is not valid
+	ErrInvalidChannelID = errors.New("invalid channel ID")
+)
+
+var (
+	// hostAliases hosts serving the channel store pages
+	hostAliases = map[string]bool{
+		Host:          true,
+		"www." + Host: true,
+		"m." + Host:   true,
+	}
+	localeRegexp    = regexp.MustCompile(`^([a-zA-Z]{2})[-_]([a-zA-Z]{2})$`)
+	channelIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9]{1,64}$`)
+)
+
+// Error URL analysis error
+type Error struct {
+	URL string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err.Error(), e.URL)
+}
+
+// Unwrap returns the error type, to be checked with errors.Is
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ChannelURL channel store URL information
+type ChannelURL struct {
+	ChannelID string
+	Slug      string
+	// Country and Language are empty when the URL does not have a locale prefix
+	Country  string
+	Language string
+}
+
+// Parse analyse a channel store URL. Supported forms, with optional trailing slashes and query strings:
+//
+//	https://channelstore.roku.com/details/<channel-id>/<slug>
+//	https://channelstore.roku.com/<lang>-<country>/details/<channel-id>/<slug>
+//	https://channelstore.roku.com/mobile/details/<channel-id>
+func Parse(raw string) (*ChannelURL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, &Error{URL: raw, Err: ErrInvalidURL}
+	}
+	if !hostAliases[strings.ToLower(parsed.Hostname())] {
+		return nil, &Error{URL: raw, Err: ErrUnsupportedHost}
+	}
+
+	var parts []string
+	for _, part := range strings.Split(parsed.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	var info ChannelURL
+	if len(parts) > 0 {
+		if locale := localeRegexp.FindStringSubmatch(parts[0]); locale != nil {
+			info.Language = strings.ToLower(locale[1])
+			info.Country = strings.ToLower(locale[2])
+			parts = parts[1:]
+		}
+	}
+	if len(parts) > 0 && strings.EqualFold(parts[0], "mobile") {
+		parts = parts[1:]
+	}
+	if len(parts) < 2 || len(parts) > 3 || !strings.EqualFold(parts[0], "details") {
+		return nil, &Error{URL: raw, Err: ErrUnsupportedPath}
+	}
+	if !channelIDRegexp.MatchString(parts[1]) {
+		return nil, &Error{URL: raw, Err: ErrInvalidChannelID}
+	}
+	info.ChannelID = strings.ToLower(parts[1])
+	if len(parts) == 3 {
+		info.Slug = parts[2]
+	}
+	return &info, nil
+}
+
+// Locale returns the URL locale, i.e.: en-gb, or an empty string
+func (c *ChannelURL) Locale() string {
+	if c.Language == "" {
+		return ""
+	}
+	return c.Language + "-" + c.Country
+}
+
+// Canonical returns the canonical channel URL, without query strings nor trailing slashes
+func (c *ChannelURL) Canonical() string {
+	var sb strings.Builder
+	sb.WriteString("https://")
+	sb.WriteString(Host)
+	if locale := c.Locale(); locale != "" {
+		sb.WriteString("/")
+		sb.WriteString(locale)
+	}
+	sb.WriteString("/details/")
+	sb.WriteString(c.ChannelID)
+	if c.Slug != "" {
+		sb.WriteString("/")
+		sb.WriteString(c.Slug)
+	}
+	return sb.String()
+}
+
+// Key returns the channel identity, the locale and the channel ID, i.e.: en-gb/12345. The slug is left out as the
+// same channel is linked with different slugs
+func (c *ChannelURL) Key() string {
+	if locale := c.Locale(); locale != "" {
+		return locale + "/" + c.ChannelID
+	}
+	return c.ChannelID
+}
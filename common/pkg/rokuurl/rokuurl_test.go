@@ -0,0 +1,77 @@
+package rokuurl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		wantErr       error
+		wantCanonical string
+		wantKey       string
+	}{
+		{
+			name:          "details page",
+			url:           "https://channelstore.roku.com/details/12345/the-channel",
+			wantCanonical: "https://channelstore.roku.com/details/12345/the-channel",
+			wantKey:       "12345",
+		},
+		{
+			name:          "locale prefix",
+			url:           "https://channelstore.roku.com/en-GB/details/12345/the-channel",
+			wantCanonical: "https://channelstore.roku.com/en-gb/details/12345/the-channel",
+			wantKey:       "en-gb/12345",
+		},
+		{
+			name:          "underscore locale",
+			url:           "https://channelstore.roku.com/es_MX/details/12345/the-channel",
+			wantCanonical: "https://channelstore.roku.com/es-mx/details/12345/the-channel",
+			wantKey:       "es-mx/12345",
+		},
+		{
+			name:          "mobile page without slug",
+			url:           "https://channelstore.roku.com/mobile/details/12345",
+			wantCanonical: "https://channelstore.roku.com/details/12345",
+			wantKey:       "12345",
+		},
+		{
+			name:          "host alias, query string, trailing slash and spaces",
+			url:           "  http://www.channelstore.roku.com/details/AbC123/the-channel/?ref=home  ",
+			wantCanonical: "https://channelstore.roku.com/details/abc123/the-channel",
+			wantKey:       "abc123",
+		},
+		{
+			name:          "mobile host",
+			url:           "https://m.channelstore.roku.com/details/12345/",
+			wantCanonical: "https://channelstore.roku.com/details/12345",
+			wantKey:       "12345",
+		},
+		{name: "relative URL", url: "/details/12345/the-channel", wantErr: ErrInvalidURL},
+		{name: "unsupported scheme", url: "ftp://channelstore.roku.com/details/12345", wantErr: ErrInvalidURL},
+		{name: "other host", url: "https://example.com/details/12345", wantErr: ErrUnsupportedHost},
+		{name: "not a details page", url: "https://channelstore.roku.com/browse/movies", wantErr: ErrUnsupportedPath},
+		{name: "missing channel ID", url: "https://channelstore.roku.com/details", wantErr: ErrUnsupportedPath},
+		{name: "extra path", url: "https://channelstore.roku.com/details/1/slug/extra", wantErr: ErrUnsupportedPath},
+		{name: "invalid channel ID", url: "https://channelstore.roku.com/details/12-345/slug", wantErr: ErrInvalidChannelID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if canonical := got.Canonical(); canonical != tt.wantCanonical {
+				t.Errorf("Canonical() = %q, want %q", canonical, tt.wantCanonical)
+			}
+			if key := got.Key(); key != tt.wantKey {
+				t.Errorf("Key() = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
@@ -2,25 +2,26 @@ package command
 
 import (
 	"context"
-	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/crawler/config"
 	"github.com/StevenRojas/natscrawler/crawler/pkg/crawler"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/health"
 	"github.com/StevenRojas/natscrawler/crawler/pkg/repository"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 // NewRootCommand creates the root command
 func NewRootCommand(ctx context.Context) *cobra.Command {
-	rootCommand := &cobra.Command{
-		Use:   "process",
-		Short: "Collect URLs and process them",
-
-		PersistentPreRunE: config.Setup,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return process(ctx)
-		},
+	rootCommand := NewCommand(ctx)
+	rootCommand.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		err := config.Setup(cmd, args)
+		if err != nil {
+			return err
+		}
+		return logging.Setup(os.Stdout, config.App.Log, "service", "crawler")
 	}
-
 	rootCommand.Flags().StringVarP(
 		&config.Filename,
 		"config",
@@ -32,7 +33,22 @@ func NewRootCommand(ctx context.Context) *cobra.Command {
 	return rootCommand
 }
 
-func process(ctx context.Context) error {
+// NewCommand creates the command that collects the queued URLs. The configuration is expected to be loaded by the
+// caller, so it can be used by other CLIs
+func NewCommand(ctx context.Context) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "process",
+		Short: "Collect URLs and process them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(ctx, cmd)
+		},
+	}
+	config.AddFlags(command)
+
+	return command
+}
+
+func process(ctx context.Context, cmd *cobra.Command) error {
 	repo, err := repository.NewRepository(config.App.Database)
 	if err != nil {
 		return err
@@ -40,14 +56,45 @@ func process(ctx context.Context) error {
 	defer func(repo repository.Repository) {
 		err := repo.Close()
 		if err != nil {
-			fmt.Printf("unable to close DB connection: %s", err.Error())
+			logging.L().Error("unable to close DB connection", "error", err)
 		}
 	}(repo)
+	if config.App.Database.Migrate {
+		err = repo.Migrate(ctx)
+		if err != nil {
+			return err
+		}
+	}
 
 	c, err := crawler.NewCrawler(repo, config.App)
 	if err != nil {
 		return err
 	}
+	// the tunable settings are reloaded when the configuration or the profiles files change
+	load := func() (config.AppConfig, error) {
+		return config.Reload(cmd)
+	}
+	reload := func(name string) {
+		logging.L().Info("configuration file changed", "file", name)
+		c.Reload(load)
+	}
+	appconfig.Watch(config.Filename, reload)
+	if config.App.Crawler.Extractor != "api" {
+		appconfig.Watch(config.App.Crawler.Profiles, reload)
+	}
+
+	// the probes are not served if the health address is not set
+	if config.App.Health.Address == "" {
+		c.Process(ctx)
+		return nil
+	}
+	healthServer := health.NewServer(config.App.Health, c.Checks())
+	go func() {
+		err := healthServer.Start()
+		if err != nil {
+			logging.L().Error("unable to start health server", "error", err)
+		}
+	}()
 	c.Process(ctx)
-	return nil
+	return healthServer.Shutdown(context.Background())
 }
\ No newline at end of file
@@ -1,66 +1,48 @@
 package config
 
 import (
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/common/pkg/natsconn"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
-	"strings"
 	"time"
 )
 
 // environmentPrefix prefix used to avoid environment variable names collisions
-const environmentPrefix = "CT_L"
+const environmentPrefix = "CT_C"
 
 var (
 	// Filename configuration file name.
 	Filename string
 	// App configuration struct
 	App AppConfig
-
-	// environmentVarList list of environment variables read by the app. The name should match with a struct field.
-	// The dots will be replaced by underscores, it will be capitalized and the environmentPrefix will be added
-	// 		i.e.: nats.host => CT_L_NATS_HOST
-	environmentVarList = []string{
-		"nats.host",
-	}
 )
 
 // AppConfig struct
 type AppConfig struct {
-	Nats       NatsServer `mapstructure:"nats"`
-	Queue      Queue      `mapstructure:"queue"`
-	Monitoring Monitoring `mapstructure:"monitor"`
-	Database   Database   `mapstructure:"database"`
-	Crawler    Crawler    `mapstructure:"crawler"`
-}
-
-// NatsServer NATS server configuration
-type NatsServer struct {
-	Host                  string `mapstructure:"host"`
-	AllowReconnect        bool   `mapstructure:"allow_reconnect"`
-	MaxReconnectAttempts  int    `mapstructure:"max_reconnect_attempts"`
-	ReconnectWaitDuration string `mapstructure:"reconnect_wait"`
-	TimeoutDuration       string `mapstructure:"timeout"`
-	ReconnectWait         time.Duration
-	Timeout               time.Duration
+	Nats       natsconn.Config     `mapstructure:"nats"`
+	Queue      natsconn.Queue      `mapstructure:"queue"`
+	Monitoring natsconn.Monitoring `mapstructure:"monitor"`
+	Database   Database            `mapstructure:"database"`
+	Crawler    Crawler             `mapstructure:"crawler"`
+	Health     Health              `mapstructure:"health"`
+	Log        logging.Config      `mapstructure:"log"`
 }
 
-// Queue NATS queue configuration
-type Queue struct {
-	Topic string `mapstructure:"topic"`
-	Group string `mapstructure:"group"`
-}
-
-// Monitoring configuration
-type Monitoring struct {
-	Topic string `mapstructure:"topic"`
+// Health health server configuration
+type Health struct {
+	// Address where /healthz and /readyz are served, the probes are disabled if it is empty
+	Address string `mapstructure:"address"`
+	// Timeout time allowed to the readiness checks
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 // Database database configuration
 type Database struct {
 	Host     string `mapstructure:"host"`
 	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
+	Password string `mapstructure:"password" secret:"true"`
 	Database string `mapstructure:"database"`
 	Port     int    `mapstructure:"port"`
 	SSLMode  string `mapstructure:"ssl_mode"`
@@ -69,55 +51,152 @@ type Database struct {
 
 // Crawler configuration
 type Crawler struct {
-	UseAPI bool `mapstructure:"use_api"`
+	// Extractor strategy used to collect the information: chromedp, api or html
+	Extractor string `mapstructure:"extractor"`
+	// Collectors number of URLs processed concurrently, the number of CPUs is used if it is 0
+	Collectors int `mapstructure:"collectors"`
+	// Deadline default time allowed to process a URL, used when the request does not define it
+	Deadline time.Duration `mapstructure:"deadline"`
+	// ShutdownGrace time allowed to the in-flight work to finish on shutdown
+	ShutdownGrace time.Duration `mapstructure:"shutdown_grace"`
+	// Profiles path to the YAML file with the extraction profiles used by the chromedp and html extractors, it is
+	// not loaded by the api extractor
+	Profiles  string     `mapstructure:"profiles"`
+	HTTP      HTTPClient `mapstructure:"http"`
+	Cache     Cache      `mapstructure:"cache"`
+	Robots    Robots     `mapstructure:"robots"`
+	RateLimit RateLimit  `mapstructure:"rate_limit"`
+}
+
+// Robots robots.txt compliance configuration
+type Robots struct {
+	Enabled bool `mapstructure:"enabled"`
+	// UserAgent name used to select the robots.txt group of rules, it is sent as the User-Agent of every request
+	// instead of the http.user_agents, so the rules checked are the ones of the crawler
+	UserAgent string        `mapstructure:"user_agent"`
+	CacheTTL  time.Duration `mapstructure:"cache_ttl"`
+}
+
+// RateLimit rate limits applied to the contacted hosts
+type RateLimit struct {
+	// HostDelay minimum time between requests to the same host, robots.txt Crawl-delay is used if longer
+	HostDelay time.Duration `mapstructure:"host_delay"`
+}
+
+// Cache response cache used to send conditional requests on repeated crawls
+type Cache struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Dir     string `mapstructure:"dir"`
+	// MaxAge time a response is cached, the entries never expire if it is 0
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// MaxEntries responses cached, the oldest ones are removed first. There is no limit if it is 0
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// HTTPClient configuration of the HTTP client used by the api and html extractors
+type HTTPClient struct {
+	Timeout             time.Duration `mapstructure:"timeout"`
+	IdleConnTimeout     time.Duration `mapstructure:"idle_conn_timeout"`
+	MaxIdleConns        int           `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `mapstructure:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int           `mapstructure:"max_conns_per_host"`
+	HTTP2               bool          `mapstructure:"http2"`
+	Gzip                bool          `mapstructure:"gzip"`
+	// Proxies outbound proxy URLs used in rotation, the environment proxy configuration is used if it is empty
+	Proxies []string `mapstructure:"proxies"`
+	// UserAgents User-Agent headers used in rotation, unless robots.txt compliance is enabled
+	UserAgents []string `mapstructure:"user_agents"`
 }
 
-// Setup bind command flags and environment variables
-// The precedence to override a configuration is: flag -> environment variable -> configuration field
+// AddFlags add a flag overriding each configuration key
+func AddFlags(cmd *cobra.Command) {
+	appconfig.AddFlags(cmd.Flags(), environmentPrefix, &App)
+}
+
+// Setup load the configuration file, overridden by the command flags and environment variables
 func Setup(cmd *cobra.Command, _ []string) error {
-	v := viper.New()
-	v.SetConfigFile(Filename)
-	v.SetConfigType("yaml")
-	v.AddConfigPath("./config")
-
-	v.SetEnvPrefix(environmentPrefix)
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	err := v.ReadInConfig()
-	if err != nil {
-		return err
-	}
+	return appconfig.Load(cmd, Filename, environmentPrefix, &App)
+}
 
-	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
-		_ = v.BindPFlag(flag.Name, cmd.Flags().Lookup(flag.Name))
-	})
-	for _, env := range environmentVarList {
-		_ = v.BindPFlag(env, cmd.Flags().Lookup(env))
+// Reload load the configuration file again, App is not modified
+func Reload(cmd *cobra.Command) (AppConfig, error) {
+	var conf AppConfig
+	err := appconfig.Load(cmd, Filename, environmentPrefix, &conf)
+	return conf, err
+}
+
+// Validate the configuration
+func (c *AppConfig) Validate() error {
+	var errs appconfig.Errors
+	errs.Merge("log", c.Log.Validate())
+	errs.Merge("nats", c.Nats.Validate())
+	errs.Merge("queue", c.Queue.Validate())
+	errs.Required("queue.group", c.Queue.Group)
+	errs.Merge("monitor", c.Monitoring.Validate())
+	if c.Health.Address != "" {
+		errs.HostPort("health.address", c.Health.Address)
+		errs.Positive("health.timeout", c.Health.Timeout)
 	}
+	errs.Merge("database", c.Database.Validate())
+	errs.Merge("crawler", c.Crawler.Validate())
+	return errs.Err()
+}
 
-	err = v.Unmarshal(&App)
-	if err != nil {
-		return err
+// Validate the database configuration
+func (d Database) Validate() error {
+	var errs appconfig.Errors
+	errs.Required("host", d.Host)
+	errs.Required("database", d.Database)
+	errs.Required("username", d.Username)
+	errs.Range("port", d.Port, 1, 65535)
+	errs.OneOf("ssl_mode", d.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	return errs.Err()
+}
+
+// Validate the crawler configuration
+func (c Crawler) Validate() error {
+	var errs appconfig.Errors
+	errs.OneOf("extractor", c.Extractor, "chromedp", "api", "html")
+	if c.Collectors < 0 {
+		errs.Add("collectors", "can not be negative")
+	}
+	if c.Extractor != "api" {
+		errs.Required("profiles", c.Profiles)
+	}
+	errs.Positive("deadline", c.Deadline)
+	errs.Positive("shutdown_grace", c.ShutdownGrace)
+	errs.Merge("http", c.HTTP.Validate())
+	if c.Cache.Enabled {
+		errs.Required("cache.dir", c.Cache.Dir)
+		errs.NotNegative("cache.max_age", c.Cache.MaxAge)
+		if c.Cache.MaxEntries < 0 {
+			errs.Add("cache.max_entries", "can not be negative")
+		}
 	}
-	err = parseDurations()
-	if err != nil {
-		return err
+	if c.Robots.Enabled {
+		errs.Required("robots.user_agent", c.Robots.UserAgent)
+		errs.NotNegative("robots.cache_ttl", c.Robots.CacheTTL)
 	}
-	return nil
+	errs.NotNegative("rate_limit.host_delay", c.RateLimit.HostDelay)
+	return errs.Err()
 }
 
-// parseDurations parse string durations (1s, 2h, etc.) into time.Duration values
-func parseDurations() error {
-	var err error
-	App.Nats.ReconnectWait, err = time.ParseDuration(App.Nats.ReconnectWaitDuration)
-	if err != nil {
-		return err
+// Validate the HTTP client configuration
+func (h HTTPClient) Validate() error {
+	var errs appconfig.Errors
+	errs.Positive("timeout", h.Timeout)
+	errs.NotNegative("idle_conn_timeout", h.IdleConnTimeout)
+	if h.MaxIdleConns < 0 {
+		errs.Add("max_idle_conns", "can not be negative")
 	}
-	App.Nats.Timeout, err = time.ParseDuration(App.Nats.TimeoutDuration)
-	if err != nil {
-		return err
+	if h.MaxIdleConnsPerHost < 0 {
+		errs.Add("max_idle_conns_per_host", "can not be negative")
 	}
-
-	return nil
+	if h.MaxConnsPerHost < 0 {
+		errs.Add("max_conns_per_host", "can not be negative")
+	}
+	for i, proxy := range h.Proxies {
+		errs.URL(fmt.Sprintf("proxies[%d]", i), proxy, "http", "https", "socks5")
+	}
+	return errs.Err()
 }
@@ -3,7 +3,9 @@ module github.com/StevenRojas/natscrawler/crawler
 go 1.18
 
 require (
+	github.com/StevenRojas/natscrawler/common v0.0.0
 	github.com/StevenRojas/natscrawler/grpcapi v0.0.0
+	github.com/chromedp/cdproto v0.0.0-20220321060548-7bc2623472b3
 	github.com/chromedp/chromedp v0.8.0
 	github.com/golang/protobuf v1.5.2
 	github.com/lib/pq v1.10.4
@@ -12,10 +14,10 @@ require (
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.10.1
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2
 )
 
 require (
-	github.com/chromedp/cdproto v0.0.0-20220321060548-7bc2623472b3 // indirect
 	github.com/chromedp/sysutil v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
@@ -38,7 +40,6 @@ require (
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/subosito/gotenv v1.2.0 // indirect
 	golang.org/x/crypto v0.0.0-20220112180741-5e0467b6c7ce // indirect
-	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2 // indirect
 	golang.org/x/sys v0.0.0-20220111092808-5a964db01320 // indirect
 	golang.org/x/text v0.3.7 // indirect
 	google.golang.org/genproto v0.0.0-20211208223120-3a66f561d7aa // indirect
@@ -50,3 +51,5 @@ require (
 )
 
 replace github.com/StevenRojas/natscrawler/grpcapi v0.0.0 => ../grpcapi
+
+replace github.com/StevenRojas/natscrawler/common v0.0.0 => ../common
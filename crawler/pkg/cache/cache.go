@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval minimum time between the removals of the expired and exceeding entries of the disk cache
+const sweepInterval = time.Minute
+
+// ErrNotModified the resource did not change since its validators were cached
+var ErrNotModified = errors.New("not modified")
+
+// Validators conditional request validators of a response, with the data extracted from it
+type Validators struct {
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"last_modified"`
+	CachedAt     time.Time `json:"cached_at"`
+	// Data extracted from the response, returned instead when the response is not modified
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Cache response validators cache keyed by canonical URL
+type Cache interface {
+	Get(key string) (Validators, bool)
+	Put(key string, v Validators) error
+}
+
+// FromResponse returns the validators sent by the server
+func FromResponse(resp *http.Response) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		CachedAt:     time.Now().UTC(),
+	}
+}
+
+// Apply set the conditional request headers
+func (v Validators) Apply(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// WithData returns the validators with the data extracted from the response encoded as JSON
+func (v Validators) WithData(data interface{}) (Validators, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return v, err
+	}
+	v.Data = encoded
+	return v, nil
+}
+
+// Decode the data extracted from the cached response
+func (v Validators) Decode(data interface{}) error {
+	return json.Unmarshal(v.Data, data)
+}
+
+// Empty returns true if the server did not send any validator
+func (v Validators) Empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Do send the request with the cached validators of the key. ErrNotModified is returned for 304 responses, with
+// the cached validators holding the data extracted from the previous response. The caller should Put the validators
+// from the response with its data once it is processed successfully
+func Do(client *http.Client, c Cache, key string, req *http.Request) (*http.Response, Validators, error) {
+	cached, ok := c.Get(key)
+	if ok {
+		cached.Apply(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, cached, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		return nil, cached, ErrNotModified
+	}
+	return resp, cached, nil
+}
+
+type diskCache struct {
+	dir        string
+	maxAge     time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	sweptAt    time.Time
+}
+
+// NewDiskCache returns a cache storing a file per key at the directory. The entries older than the max age are
+// removed, and the oldest ones once there are more than the max entries, no limit is applied if they are 0
+func NewDiskCache(dir string, maxAge time.Duration, maxEntries int) (Cache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	d := &diskCache{dir: dir, maxAge: maxAge, maxEntries: maxEntries}
+	err = d.sweep()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Get returns the validators of the key, the ones without data are ignored as a not modified response could not
+// be served, and the expired ones are removed
+func (d *diskCache) Get(key string) (Validators, bool) {
+	var v Validators
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return v, false
+	}
+	err = json.Unmarshal(data, &v)
+	if err != nil || v.Empty() || len(v.Data) == 0 {
+		return Validators{}, false
+	}
+	if d.maxAge > 0 && time.Since(v.CachedAt) > d.maxAge {
+		_ = os.Remove(d.path(key))
+		return Validators{}, false
+	}
+	return v, true
+}
+
+// Put store the validators of the key, responses without validators are not stored
+func (d *diskCache) Put(key string, v Validators) error {
+	if v.Empty() {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	err = os.Rename(tmp.Name(), d.path(key))
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	due := time.Since(d.sweptAt) >= sweepInterval
+	d.mu.Unlock()
+	if due {
+		return d.sweep()
+	}
+	return nil
+}
+
+// sweep remove the entries older than the max age, then the oldest entries over the max entries
+func (d *diskCache) sweep() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweptAt = time.Now()
+	if d.maxAge <= 0 && d.maxEntries <= 0 {
+		return nil
+	}
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		path    string
+		modTime time.Time
+	}
+	var entries []entry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(d.dir, dirEntry.Name())
+		if d.maxAge > 0 && time.Since(info.ModTime()) > d.maxAge {
+			_ = os.Remove(path)
+			continue
+		}
+		entries = append(entries, entry{path: path, modTime: info.ModTime()})
+	}
+	if d.maxEntries <= 0 || len(entries) <= d.maxEntries {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.After(entries[j].modTime)
+	})
+	for _, e := range entries[d.maxEntries:] {
+		_ = os.Remove(e.path)
+	}
+	return nil
+}
+
+func (d *diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+type noCache struct{}
+
+// NewNoCache returns a cache that does not store anything
+func NewNoCache() Cache {
+	return noCache{}
+}
+
+// Get always misses
+func (noCache) Get(string) (Validators, bool) {
+	return Validators{}, false
+}
+
+// Put does nothing
+func (noCache) Put(string, Validators) error {
+	return nil
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+const lastModified = "Fri, 01 Apr 2022 10:00:00 GMT"
+
+func TestDo(t *testing.T) {
+	// the server answers 304 to the requests with the current validators
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte("channel"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		// cached validators of the URL, if any
+		cached      *Validators
+		wantHeaders map[string]string
+		wantErr     error
+	}{
+		{name: "not cached", wantHeaders: map[string]string{"If-None-Match": "", "If-Modified-Since": ""}},
+		{
+			name:        "etag",
+			cached:      &Validators{ETag: `"v1"`, Data: []byte(`"cached"`)},
+			wantHeaders: map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": ""},
+			wantErr:     ErrNotModified,
+		},
+		{
+			name:        "last modified",
+			cached:      &Validators{LastModified: lastModified, Data: []byte(`"cached"`)},
+			wantHeaders: map[string]string{"If-None-Match": "", "If-Modified-Since": lastModified},
+			wantErr:     ErrNotModified,
+		},
+		{
+			name:        "changed",
+			cached:      &Validators{ETag: `"v0"`, Data: []byte(`"cached"`)},
+			wantHeaders: map[string]string{"If-None-Match": `"v0"`, "If-Modified-Since": ""},
+		},
+		{
+			name:        "cached without data",
+			cached:      &Validators{ETag: `"v1"`},
+			wantHeaders: map[string]string{"If-None-Match": "", "If-Modified-Since": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewDiskCache(t.TempDir(), 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.cached != nil {
+				if err := c.Put(server.URL, *tt.cached); err != nil {
+					t.Fatal(err)
+				}
+			}
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, cached, err := Do(server.Client(), c, server.URL, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := req.Header.Get(name); got != want {
+					t.Errorf("request header %s = %q, want %q", name, got, want)
+				}
+			}
+			if err != nil {
+				// the not modified response is served from the cached data
+				var data string
+				if err := cached.Decode(&data); err != nil || data != "cached" {
+					t.Errorf("cached data = %q (%v), want %q", data, err, "cached")
+				}
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || FromResponse(resp).ETag != `"v1"` {
+				t.Errorf("Do() = %s with ETag %q, want 200 with the current ETag", resp.Status, FromResponse(resp).ETag)
+			}
+		})
+	}
+}
+
+func TestDiskCacheMaxAge(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxAge   time.Duration
+		cachedAt time.Time
+		want     bool
+	}{
+		{name: "no max age", cachedAt: time.Now().Add(-1000 * time.Hour), want: true},
+		{name: "fresh", maxAge: time.Hour, cachedAt: time.Now().Add(-time.Minute), want: true},
+		{name: "expired", maxAge: time.Hour, cachedAt: time.Now().Add(-2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewDiskCache(t.TempDir(), tt.maxAge, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.Put("key", Validators{ETag: `"v1"`, CachedAt: tt.cachedAt, Data: []byte(`{}`)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := c.Get("key"); ok != tt.want {
+				t.Errorf("Get() = %v, want %v", ok, tt.want)
+			}
+			// the expired entries are removed
+			_, err = os.Stat(c.(*diskCache).path("key"))
+			if exists := err == nil; exists != tt.want {
+				t.Errorf("entry file exists = %v, want %v", exists, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiskCacheSweep(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxAge     time.Duration
+		maxEntries int
+		// ages of the entries, named by their index
+		ages []time.Duration
+		want []bool
+	}{
+		{name: "no limits", ages: []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}, want: []bool{true, true, true}},
+		{
+			name:   "expired entries",
+			maxAge: 90 * time.Minute,
+			ages:   []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour},
+			want:   []bool{true, false, false},
+		},
+		{
+			name:       "oldest entries over the max",
+			maxEntries: 2,
+			ages:       []time.Duration{2 * time.Hour, 3 * time.Hour, time.Hour},
+			want:       []bool{true, false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			unlimited, err := NewDiskCache(dir, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys := []string{"0", "1", "2"}
+			for i, age := range tt.ages {
+				err := unlimited.Put(keys[i], Validators{ETag: `"v1"`, CachedAt: time.Now(), Data: []byte(`{}`)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				modTime := time.Now().Add(-age)
+				if err := os.Chtimes(unlimited.(*diskCache).path(keys[i]), modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// the entries are swept when the cache is created
+			if _, err := NewDiskCache(dir, tt.maxAge, tt.maxEntries); err != nil {
+				t.Fatal(err)
+			}
+			for i, key := range keys {
+				_, err := os.Stat(unlimited.(*diskCache).path(key))
+				if exists := err == nil; exists != tt.want[i] {
+					t.Errorf("entry %s exists = %v, want %v", key, exists, tt.want[i])
+				}
+			}
+		})
+	}
+}
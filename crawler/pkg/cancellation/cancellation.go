@@ -0,0 +1,103 @@
+package cancellation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cancelledTTL time a cancellation is kept for requests that are not in-flight yet, the cancellations of the
+// requests processed by other crawlers are never matched here
+const cancelledTTL = time.Hour
+
+// Registry keeps track of in-flight requests so they can be cancelled by request ID. A request is only cancelled
+// by the client that queued it, and a cancellation only applies to the URLs queued before it was requested, so a
+// request ID submitted again is processed
+type Registry interface {
+	Register(ctx context.Context, requestID string, client string, queuedAt time.Time) (context.Context, context.CancelFunc)
+	Cancel(requestID string, client string, requestedAt time.Time)
+	Cancelled(requestID string, client string, queuedAt time.Time) bool
+}
+
+type registry struct {
+	mu        sync.Mutex
+	inFlight  map[string]inFlight
+	cancelled map[string]cancellation
+}
+
+// inFlight request being processed
+type inFlight struct {
+	client   string
+	queuedAt time.Time
+	cancel   context.CancelFunc
+}
+
+// cancellation of a request that is not processed yet
+type cancellation struct {
+	client      string
+	requestedAt time.Time
+	receivedAt  time.Time
+}
+
+// applies reports whether the cancellation applies to the URL of the client queued at the time. URLs without
+// queued time are queued by listeners not sending it, the cancellation applies to them
+func (c cancellation) applies(client string, queuedAt time.Time) bool {
+	return c.client == client && !queuedAt.After(c.requestedAt)
+}
+
+// NewRegistry returns a new cancellation registry
+func NewRegistry() Registry {
+	return &registry{
+		inFlight:  map[string]inFlight{},
+		cancelled: map[string]cancellation{},
+	}
+}
+
+// Register returns a context that is cancelled when the request is cancelled by its client. The returned cancel
+// function should be called once the request is processed
+func (r *registry) Register(ctx context.Context, requestID string, client string, queuedAt time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	r.inFlight[requestID] = inFlight{client: client, queuedAt: queuedAt, cancel: cancel}
+	r.mu.Unlock()
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.inFlight, requestID)
+		r.mu.Unlock()
+		cancel()
+	}
+}
+
+// Cancel the in-flight request, or remember the cancellation for a request that is still queued. The time it was
+// requested is the current time if it is not known
+func (r *registry) Cancel(requestID string, client string, requestedAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, c := range r.cancelled {
+		if now.Sub(c.receivedAt) > cancelledTTL {
+			delete(r.cancelled, id)
+		}
+	}
+	if requestedAt.IsZero() {
+		requestedAt = now
+	}
+	c := cancellation{client: client, requestedAt: requestedAt, receivedAt: now}
+	r.cancelled[requestID] = c
+	if request, ok := r.inFlight[requestID]; ok && c.applies(request.client, request.queuedAt) {
+		request.cancel()
+	}
+}
+
+// Cancelled returns true if the request queued at the time was cancelled by its client. The cancellation is
+// forgotten once it is matched
+func (r *registry) Cancelled(requestID string, client string, queuedAt time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cancelled[requestID]
+	if !ok || !c.applies(client, queuedAt) {
+		return false
+	}
+	delete(r.cancelled, requestID)
+	return true
+}
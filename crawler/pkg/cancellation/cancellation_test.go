@@ -0,0 +1,111 @@
+package cancellation
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRegistryCancelled(t *testing.T) {
+	queued := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		client      string
+		requestedAt time.Time
+		// checks of the request, in order, with the client and queued time
+		checks []check
+	}{
+		{
+			name:        "queued before the cancellation",
+			requestedAt: queued.Add(time.Second),
+			checks:      []check{{queuedAt: queued, want: true}},
+		},
+		{
+			name:        "submitted again after the cancellation",
+			requestedAt: queued.Add(time.Second),
+			checks: []check{
+				{queuedAt: queued.Add(2 * time.Second), want: false},
+				{queuedAt: queued, want: true},
+			},
+		},
+		{
+			name:        "forgotten once matched",
+			requestedAt: queued.Add(time.Second),
+			checks: []check{
+				{queuedAt: queued, want: true},
+				{queuedAt: queued, want: false},
+			},
+		},
+		{
+			name:        "cancelled by another client",
+			client:      "other",
+			requestedAt: queued.Add(time.Second),
+			checks: []check{
+				{client: "client", queuedAt: queued, want: false},
+				{client: "other", queuedAt: queued, want: true},
+			},
+		},
+		{
+			name:        "queued time unknown",
+			requestedAt: queued,
+			checks:      []check{{want: true}},
+		},
+		{
+			name:   "cancellation time unknown",
+			checks: []check{{queuedAt: time.Now().Add(-time.Minute), want: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			client := tt.client
+			if client == "" {
+				client = "client"
+			}
+			r.Cancel("request", client, tt.requestedAt)
+			for i, c := range tt.checks {
+				if c.client == "" {
+					c.client = "client"
+				}
+				if got := r.Cancelled("request", c.client, c.queuedAt); got != c.want {
+					t.Errorf("Cancelled() #%d = %v, want %v", i, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+// check of a request cancellation
+type check struct {
+	client   string
+	queuedAt time.Time
+	want     bool
+}
+
+func TestRegistryCancelInFlight(t *testing.T) {
+	queued := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		client        string
+		requestedAt   time.Time
+		wantCancelled bool
+	}{
+		{name: "by the client", client: "client", requestedAt: queued.Add(time.Second), wantCancelled: true},
+		{name: "by another client", client: "other", requestedAt: queued.Add(time.Second)},
+		{name: "requested before it was queued", client: "client", requestedAt: queued.Add(-time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			ctx, cancel := r.Register(context.Background(), "request", "client", queued)
+			defer cancel()
+			r.Cancel("request", tt.client, tt.requestedAt)
+			if got := ctx.Err() != nil; got != tt.wantCancelled {
+				t.Errorf("context cancelled = %v, want %v", got, tt.wantCancelled)
+			}
+			if got := r.Cancelled("request", "client", queued); got != tt.wantCancelled {
+				t.Errorf("Cancelled() = %v, want %v", got, tt.wantCancelled)
+			}
+		})
+	}
+}
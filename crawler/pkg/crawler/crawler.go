@@ -3,29 +3,54 @@ package crawler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/common/pkg/natsconn"
+	"github.com/StevenRojas/natscrawler/common/pkg/rokuurl"
 	"github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cache"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cancellation"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/extractor"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/health"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/httpclient"
 	"github.com/StevenRojas/natscrawler/crawler/pkg/model"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/profile"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/ratelimit"
 	"github.com/StevenRojas/natscrawler/crawler/pkg/repository"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/robots"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/roku"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/telemetry"
 	"github.com/StevenRojas/natscrawler/grpcapi/pkg/crawler/pb"
+	"github.com/chromedp/cdproto/emulation"
 	"github.com/chromedp/chromedp"
 	"github.com/golang/protobuf/proto"
 	"github.com/nats-io/nats.go"
 	"io"
-	"log"
-	"math"
 	"net/http"
 	"net/url"
 	"os"
-	"runtime"
-	"strconv"
+	"path/filepath"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
 )
 
+// storeCheckInterval time the result of the channel store check is reused, so the readiness probes do not send a
+// request to the channel store each time
+const storeCheckInterval = time.Minute
+
+const (
+	extractorChromedp = "chromedp"
+	extractorAPI      = "api"
+	extractorHTML     = "html"
+)
+
 type Crawler interface {
 	Process(ctx context.Context)
+	Checks() map[string]health.Check
+	Reload(load func() (config.AppConfig, error))
 }
 
 type chromeService struct {
@@ -38,43 +63,127 @@ type chromeService struct {
 }
 
 type crawlerService struct {
-	repo       repository.Repository
-	natsClient *nats.Conn
-	topic      string
-	group      string
-	wsUrl      string
-	useAPI     bool
+	repo        repository.Repository
+	natsClient  *nats.Conn
+	topic       string
+	group       string
+	cancelTopic string
+	registry    cancellation.Registry
+	wsUrl       string
+	mode        string
+	httpClient  *http.Client
+	collectors  collectorPool
+	// settingsMu guards the settings that can be changed by Reload
+	settingsMu sync.RWMutex
+	// conf configuration last applied
+	conf          config.AppConfig
+	settings      config.Crawler
+	profiles      *profile.Set
+	htmlExtractor extractor.HTMLExtractor
+	apiClient     roku.Client
+	telemetry     telemetry.Telemetry
+	limiter       ratelimit.Limiter
+	robots        robots.Robots
+	// userAgent robots user agent sent by the chromedp extractor, empty if robots.txt compliance is disabled
+	userAgent  string
+	storeCheck storeCheck
+}
+
+// storeCheck last result of the channel store check
+type storeCheck struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
 }
 
 func NewCrawler(repo repository.Repository, conf config.AppConfig) (Crawler, error) {
-	log.SetOutput(os.Stdout)
-	log.SetFlags(log.Lmsgprefix)
 
 	var webSocketDebuggerUrl string
-	if !conf.Crawler.UseAPI {
-		cs, err := getChromeInfo()
+	switch conf.Crawler.Extractor {
+	case extractorChromedp:
+		cs, err := getChromeInfo(context.Background())
 		if err != nil {
 			return nil, err
 		}
 		webSocketDebuggerUrl = cs.WebSocketDebuggerUrl
+		logging.L().Info("connecting to chrome", "url", webSocketDebuggerUrl)
+	case extractorAPI, extractorHTML:
+	default:
+		return nil, fmt.Errorf("unknown extractor: %s", conf.Crawler.Extractor)
+	}
+
+	profiles, err := loadProfiles(conf.Crawler.Extractor, conf.Crawler.Profiles)
+	if err != nil {
+		return nil, err
+	}
+	httpConf := conf.Crawler.HTTP
+	if conf.Crawler.Robots.Enabled {
+		// the robots.txt rules are checked for the robots user agent, so it is the one sent
+		httpConf.UserAgents = []string{conf.Crawler.Robots.UserAgent}
+	}
+	httpClient, err := httpclient.NewClient(httpConf)
+	if err != nil {
+		return nil, err
+	}
+	responseCache := cache.NewNoCache()
+	if conf.Crawler.Cache.Enabled {
+		responseCache, err = cache.NewDiskCache(filepath.Join(conf.Crawler.Cache.Dir, conf.Crawler.Extractor),
+			conf.Crawler.Cache.MaxAge, conf.Crawler.Cache.MaxEntries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create response cache: %w", err)
+		}
+	}
+
+	limiter := ratelimit.NewLimiter(conf.Crawler.RateLimit.HostDelay)
+	var robotsChecker robots.Robots
+	var userAgent string
+	if conf.Crawler.Robots.Enabled {
+		robotsChecker = robots.NewRobots(httpClient, limiter, conf.Crawler.Robots.UserAgent, conf.Crawler.Robots.CacheTTL)
+		userAgent = conf.Crawler.Robots.UserAgent
 	}
 
-	nc, err := connectToNats(conf)
+	nc, err := natsconn.Connect(conf.Nats, conf.Crawler.ShutdownGrace, natsconn.Handlers{})
 	if err != nil {
 		return nil, err
 	}
 	return &crawlerService{
-		repo:       repo,
-		natsClient: nc,
-		topic:      conf.Queue.Topic,
-		group:      conf.Queue.Group,
-		wsUrl:      webSocketDebuggerUrl,
-		useAPI:     conf.Crawler.UseAPI,
+		repo:          repo,
+		natsClient:    nc,
+		topic:         conf.Queue.Topic,
+		group:         conf.Queue.Group,
+		cancelTopic:   conf.Queue.CancelTopic,
+		conf:          conf,
+		registry:      cancellation.NewRegistry(),
+		wsUrl:         webSocketDebuggerUrl,
+		mode:          conf.Crawler.Extractor,
+		httpClient:    httpClient,
+		collectors:    collectorPool{size: collectorsCount(conf.Crawler.Collectors)},
+		settings:      conf.Crawler,
+		profiles:      profiles,
+		htmlExtractor: extractor.NewHTMLExtractor(httpClient, responseCache),
+		apiClient:     roku.NewClient(httpClient, responseCache),
+		telemetry:     telemetry.NewTelemetry(nc, conf.Monitoring.Topic, conf.Monitoring.Interval),
+		limiter:       limiter,
+		robots:        robotsChecker,
+		userAgent:     userAgent,
 	}, nil
 }
 
-func getChromeInfo() (*chromeService, error) {
-	req, err := http.NewRequest("GET", "http://chrome:9222/json/version", nil)
+// loadProfiles returns the extraction profiles of the chromedp and html extractors, nil for the api extractor which
+// does not use them
+func loadProfiles(extractor string, file string) (*profile.Set, error) {
+	if extractor == extractorAPI {
+		return nil, nil
+	}
+	profiles, err := profile.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load extraction profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+func getChromeInfo(ctx context.Context) (*chromeService, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://chrome:9222/json/version", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +195,7 @@ func getChromeInfo() (*chromeService, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("unable to get Chrome service information: %s", resp.Status)
 	}
@@ -99,211 +209,439 @@ func getChromeInfo() (*chromeService, error) {
 		return nil, err
 	}
 	cs.WebSocketDebuggerUrl = strings.Replace(cs.WebSocketDebuggerUrl, "localhost", "chrome:9222", 1)
-	log.Print("\033[H\033[2J")
-	log.Printf("Connecting to chrome at %s", cs.WebSocketDebuggerUrl)
 	return &cs, nil
 }
 
-// Process get URL from queue, collect information and store it, using Fan-In Fan-Out pattern
+// Checks returns the readiness checks of the crawler dependencies: NATS, the DB, and Chrome or the channel store
+// depending on the extractor
+func (c *crawlerService) Checks() map[string]health.Check {
+	checks := map[string]health.Check{
+		"nats":     c.checkNats,
+		"database": c.repo.Ping,
+	}
+	if c.mode == extractorChromedp {
+		checks["chrome"] = func(ctx context.Context) error {
+			_, err := getChromeInfo(ctx)
+			return err
+		}
+	} else {
+		checks["api"] = c.checkStore
+	}
+	return checks
+}
+
+// checkNats the connection is not ready while it is reconnecting or draining
+func (c *crawlerService) checkNats(_ context.Context) error {
+	status := c.natsClient.Status()
+	if status != nats.CONNECTED {
+		return fmt.Errorf("NATS connection is %s", status)
+	}
+	return nil
+}
+
+// checkStore the channel store is reachable, any HTTP response is fine. The result is reused for the check interval
+// and the concurrent probes wait for the same request
+func (c *crawlerService) checkStore(ctx context.Context) error {
+	c.storeCheck.mu.Lock()
+	defer c.storeCheck.mu.Unlock()
+	if time.Since(c.storeCheck.checkedAt) < storeCheckInterval {
+		return c.storeCheck.err
+	}
+	err := c.requestStore(ctx)
+	c.storeCheck.checkedAt = time.Now()
+	c.storeCheck.err = err
+	return err
+}
+
+// requestStore send a request to the channel store home page
+func (c *crawlerService) requestStore(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+rokuurl.Host+"/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Process get URL from queue, collect information and store it, using Fan-In Fan-Out pattern.
+// When the context is done the queue is drained, so it returns once the in-flight work is stored
 func (c *crawlerService) Process(ctx context.Context) {
+	// workCtx is not cancelled on shutdown, so the in-flight work can finish within the grace period
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
 	messages := make(chan model.UrlInfo)
-	numCollectors := runtime.NumCPU()
-	// Fan-Out the work to multiple collectors
-	collectors := make([]<-chan model.UrlInfo, numCollectors)
-	for i := 0; i < numCollectors; i++ {
-		collectors[i] = c.collect(ctx, messages)
-	}
-	// Fan-In the results and store them
-	go c.storeResults(ctx, collectors...)
+	stop := make(chan struct{})
+	var storing sync.WaitGroup
+	// Fan-Out the work to multiple collectors and Fan-In their results to store them
+	c.collectors.Start(func(id int, quit <-chan struct{}) {
+		storing.Add(1)
+		results := c.collect(workCtx, id, messages, stop, quit)
+		go func() {
+			defer storing.Done()
+			c.storeResults(id, results)
+		}()
+	})
+	go c.telemetry.Start(ctx)
 
 	// Listen for queue and produce messages for collectors
-	sub, err := c.natsClient.QueueSubscribe(c.topic, c.group, func(m *nats.Msg) {
+	_, err := c.natsClient.QueueSubscribe(c.topic, c.group, func(m *nats.Msg) {
 		var request pb.UrlRequest
 		err := proto.Unmarshal(m.Data, &request)
 		if err != nil {
-			log.Printf("unable to parse incomming message %s\n", err)
+			logging.L().Error("unable to parse incoming message", "error", err)
 		} else {
+			deadline := c.currentSettings().Deadline.Milliseconds()
+			if request.DeadlineMs > 0 {
+				deadline = request.DeadlineMs
+			}
 			select {
-			case <-ctx.Done():
+			case <-workCtx.Done():
 				return
 			case messages <- model.UrlInfo{
 				RequestID: request.RequestId,
+				JobID:     m.Header.Get(logging.JobIDHeader),
+				Client:    m.Header.Get(natsconn.ClientHeader),
+				QueuedAt:  sentAt(m),
 				Url:       request.Url,
 				Stats: model.Stats{
-					Waiting: model.Times{StartAt: time.Now().UTC()},
+					Waiting:  model.Times{StartAt: time.Now().UTC()},
+					Deadline: deadline,
 				},
 			}:
 			}
 		}
 	})
 	if err != nil {
-		log.Fatal("Error establishing connection to NATS:", err)
+		logging.L().Error("unable to subscribe to the queue", "topic", c.topic, "error", err)
+		os.Exit(1)
 	}
 
-	defer func(sub *nats.Subscription) {
-		err := sub.Unsubscribe()
+	// Listen for cancellations, every crawler instance gets them
+	_, err = c.natsClient.Subscribe(c.cancelTopic, func(m *nats.Msg) {
+		var request pb.CancelRequest
+		err := proto.Unmarshal(m.Data, &request)
 		if err != nil {
-			log.Fatal("unable to unsubscribe from NATS:", err)
+			logging.L().Error("unable to parse cancel message", "error", err)
+			return
 		}
-		close(messages)
-	}(sub)
+		client := m.Header.Get(natsconn.ClientHeader)
+		logging.L().Info("cancelling request", "request_id", request.RequestId, "client", client)
+		c.registry.Cancel(request.RequestId, client, sentAt(m))
+	})
+	if err != nil {
+		logging.L().Error("unable to subscribe to the cancellations", "topic", c.cancelTopic, "error", err)
+		os.Exit(1)
+	}
+
 	<-ctx.Done()
+	c.drain(stop, &storing, cancelWork)
+}
+
+// drain stop getting messages from the queue, let the in-flight work finish and wait for the pending results to be
+// stored. The in-flight work is cancelled if it does not finish within the grace period
+func (c *crawlerService) drain(stop chan struct{}, storing *sync.WaitGroup, cancelWork context.CancelFunc) {
+	logging.L().Info("draining queue subscriptions")
+	grace := time.NewTimer(c.currentSettings().ShutdownGrace)
+	defer grace.Stop()
+
+	// the connection is closed once the subscriptions are drained and the pending messages are delivered
+	err := natsconn.Drain(c.natsClient)
+	if err != nil {
+		logging.L().Error("unable to drain NATS connection", "error", err)
+	}
+	logging.L().Info("NATS connection closed, waiting for in-flight work")
+
+	c.collectors.Stop()
+	close(stop)
+	stored := make(chan struct{})
+	go func() {
+		storing.Wait()
+		close(stored)
+	}()
+	select {
+	case <-stored:
+	case <-grace.C:
+		logging.L().Warn("shutdown grace period expired, cancelling in-flight work")
+		cancelWork()
+		<-stored
+	}
+	logging.L().Info("pending results stored")
 }
 
-func (c *crawlerService) collect(ctx context.Context, messages <-chan model.UrlInfo) <-chan model.UrlInfo {
+// collect process the messages until the crawler stops or the collector is removed (quit), the results are sent to
+// the returned channel
+func (c *crawlerService) collect(ctx context.Context, collectorID int, messages <-chan model.UrlInfo, stop <-chan struct{}, quit <-chan struct{}) <-chan model.UrlInfo {
 	resultChannel := make(chan model.UrlInfo)
 	go func() {
-		for message := range messages {
-			log.Println("Got task request on:", message.RequestID, message.Url)
-			if c.useAPI {
-				resultChannel <- doAPI(ctx, message)
+		defer close(resultChannel)
+		for {
+			var message model.UrlInfo
+			select {
+			case <-stop:
+				return
+			case <-quit:
+				return
+			case message = <-messages:
+			}
+			logger := messageLogger(message, collectorID)
+			logger.Info("processing URL")
+			result := c.extract(logging.NewContext(ctx, logger), message)
+			c.telemetry.Inc(telemetry.Processed)
+			if result.Success {
+				c.telemetry.Inc(telemetry.Succeeded)
 			} else {
-				resultChannel <- c.doCrawler(ctx, message)
+				c.telemetry.Inc(telemetry.Failed)
 			}
+			resultChannel <- result
 		}
-		close(resultChannel)
 	}()
 	return resultChannel
 }
 
-func (c *crawlerService) storeResults(ctx context.Context, collectors ...<-chan model.UrlInfo) {
-	var wg sync.WaitGroup
-	wg.Add(len(collectors))
-	multiplex := func(responses <-chan model.UrlInfo, collectorID int) {
-		defer wg.Done()
-		for urlInfo := range responses {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				urlInfo.Stats.CollectorID = collectorID
-				urlInfo.Stats.Collector.EndAt = time.Now().UTC()
-				urlInfo.Stats.Collector.Duration = time.Since(urlInfo.Stats.Collector.StartAt).Milliseconds()
-				err := c.repo.AddURL(ctx, urlInfo)
-				if err != nil {
-					log.Printf("error storing URL info in the DB: %s\n", err.Error())
-				}
-			}
+// extract collect the URL information with the configured extractor, within the message deadline. A panic while
+// processing the message is recovered and returned as a failed result, so the collector keeps processing
+func (c *crawlerService) extract(ctx context.Context, message model.UrlInfo) (result model.UrlInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.FromContext(ctx).Error("recovered from panic", "panic", r, "stack", string(debug.Stack()))
+			c.telemetry.Inc(telemetry.Panics)
+			result = message
+			result.Success = false
+			result.LastError = fmt.Sprintf("%s: %v", model.ErrorClassPanic, r)
 		}
+	}()
+	message.Stats.Waiting.EndAt = time.Now().UTC()
+	message.Stats.Waiting.Duration = time.Since(message.Stats.Waiting.StartAt).Milliseconds()
+	message.Stats.Collector.StartAt = time.Now().UTC()
+	if c.registry.Cancelled(message.RequestID, message.Client, message.QueuedAt) {
+		message.Success = false
+		message.LastError = fmt.Sprintf("%s: request cancelled before processing", model.ErrorClassCancelled)
+		return message
 	}
-	for id, collector := range collectors {
-		go multiplex(collector, id)
+
+	ctx, cancel := c.registry.Register(ctx, message.RequestID, message.Client, message.QueuedAt)
+	defer cancel()
+	if message.Stats.Deadline > 0 {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithTimeout(ctx, time.Duration(message.Stats.Deadline)*time.Millisecond)
+		defer cancelDeadline()
 	}
-	go func() {
-		wg.Wait()
-	}()
+	switch c.mode {
+	case extractorAPI:
+		result = c.doAPI(ctx, message)
+	case extractorHTML:
+		result = c.doHTML(ctx, message)
+	default:
+		result = c.doCrawler(ctx, message)
+	}
+	if result.Success {
+		return result
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Stats.DeadlineExceeded = true
+	} else if c.registry.Cancelled(message.RequestID, message.Client, message.QueuedAt) {
+		result.LastError = fmt.Sprintf("%s: %s", model.ErrorClassCancelled, result.LastError)
+	}
+	return result
+}
+
+// sentAt returns the time the listener published the message, zero if it is unknown
+func sentAt(m *nats.Msg) time.Time {
+	at, err := time.Parse(time.RFC3339Nano, m.Header.Get(natsconn.SentAtHeader))
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// storeResults store the results of a collector, it returns once the collector results channel is closed
+func (c *crawlerService) storeResults(collectorID int, results <-chan model.UrlInfo) {
+	for urlInfo := range results {
+		urlInfo.Stats.CollectorID = collectorID
+		urlInfo.Stats.Collector.EndAt = time.Now().UTC()
+		urlInfo.Stats.Collector.Duration = time.Since(urlInfo.Stats.Collector.StartAt).Milliseconds()
+		logger := messageLogger(urlInfo, collectorID)
+		// results are stored even during shutdown, so they are not lost
+		err := c.repo.AddURL(context.Background(), urlInfo)
+		if err != nil {
+			logger.Error("unable to store URL info in the DB", "error", err)
+			continue
+		}
+		logger.Info("URL stored", "success", urlInfo.Success, "app_name", urlInfo.AppName,
+			"duration_ms", urlInfo.Stats.Collector.Duration, "error", urlInfo.LastError)
+	}
+}
+
+// messageLogger returns a logger with the fields correlating the records of a URL processing
+func messageLogger(message model.UrlInfo, collectorID int) logging.Logger {
+	return logging.L().With(
+		"request_id", message.RequestID,
+		"job_id", message.JobID,
+		"collector_id", collectorID,
+		"url", message.Url,
+	)
 }
 
 func (c *crawlerService) doCrawler(ctx context.Context, urlInfo model.UrlInfo) model.UrlInfo {
-	ctx, cancel := chromedp.NewContext(ctx)
-	defer cancel()
-	urlInfo.Stats.Waiting.EndAt = time.Now().UTC()
-	urlInfo.Stats.Waiting.Duration = time.Since(urlInfo.Stats.Waiting.StartAt).Milliseconds()
-	urlInfo.Stats.Collector.StartAt = time.Now().UTC()
 	urlInfo.Success = false
 	select {
 	case <-ctx.Done():
 		urlInfo.LastError = ctx.Err().Error()
 		return urlInfo
 	default:
+		p, err := c.profileSet().Match(urlInfo.Url)
+		if err != nil {
+			urlInfo.LastError = err.Error()
+			return urlInfo
+		}
+		err = c.allow(ctx, urlInfo.Url)
+		if err != nil {
+			urlInfo.LastError = err.Error()
+			return urlInfo
+		}
 		var name string
 		var ratingValue string
 		var ratingCount string
-		ctx, _ = chromedp.NewRemoteAllocator(ctx, c.wsUrl)
-		ctxt, _ := chromedp.NewContext(ctx)
-		err := chromedp.Run(ctxt,
-			chromedp.Navigate(urlInfo.Url),
-			chromedp.WaitReady(`.Roku-User-Channels`),
-			chromedp.Text(`h1[itemprop="name"]`, &name, chromedp.NodeVisible),
-			chromedp.Text(`span[itemprop="averageRating"]`, &ratingValue, chromedp.NodeVisible),
-			chromedp.Text(`small[itemprop="starRating"]`, &ratingCount, chromedp.NodeVisible),
+		var actions []chromedp.Action
+		if c.userAgent != "" {
+			// the robots.txt rules are checked for the robots user agent, so it is the one sent
+			actions = append(actions, emulation.SetUserAgentOverride(c.userAgent))
+		}
+		actions = append(actions, chromedp.Navigate(urlInfo.Url))
+		if p.WaitSelector != "" {
+			actions = append(actions, chromedp.WaitReady(p.WaitSelector))
+		}
+		actions = append(actions,
+			fieldAction(p.Fields.Name, &name),
+			fieldAction(p.Fields.Rating, &ratingValue),
 		)
-
-		if err != nil {
-			urlInfo.LastError = err.Error()
-			return urlInfo
+		if p.Fields.RatingCount.Selector != "" {
+			actions = append(actions, fieldAction(p.Fields.RatingCount, &ratingCount))
 		}
-		rating, err := strconv.ParseFloat(ratingValue, 64)
+		allocatorCtx, cancelAllocator := chromedp.NewRemoteAllocator(ctx, c.wsUrl)
+		defer cancelAllocator()
+		browserCtx, cancelBrowser := chromedp.NewContext(allocatorCtx)
+		defer cancelBrowser()
+		err = chromedp.Run(browserCtx, actions...)
 		if err != nil {
 			urlInfo.LastError = err.Error()
 			return urlInfo
 		}
 
-		temp := strings.Replace(ratingCount, ratingValue, "", 1)
-		temp = strings.Trim(strings.Replace(temp, "ratings", "", 1), " \n")
-		count, err := strconv.Atoi(temp)
+		result, err := extractor.FromValues(p, name, ratingValue, ratingCount)
 		if err != nil {
 			urlInfo.LastError = err.Error()
 			return urlInfo
 		}
-		urlInfo.AppName = name
-		urlInfo.Rating = rating
-		urlInfo.RatingCount = count
+		urlInfo.AppName = result.Name
+		urlInfo.Rating = result.Rating
+		urlInfo.RatingCount = result.RatingCount
 		urlInfo.Success = true
 		return urlInfo
 	}
 }
 
-func doAPI(ctx context.Context, urlInfo model.UrlInfo) model.UrlInfo {
-	urlInfo.Stats.Waiting.EndAt = time.Now().UTC()
-	urlInfo.Stats.Waiting.Duration = time.Since(urlInfo.Stats.Waiting.StartAt).Milliseconds()
-	urlInfo.Stats.Collector.StartAt = time.Now().UTC()
+// fieldAction returns the chromedp action that reads the field text or attribute
+func fieldAction(f profile.Field, value *string) chromedp.Action {
+	if f.Attribute != "" {
+		return chromedp.AttributeValue(f.Selector, f.Attribute, value, nil, chromedp.NodeVisible)
+	}
+	return chromedp.Text(f.Selector, value, chromedp.NodeVisible)
+}
+
+func (c *crawlerService) doHTML(ctx context.Context, urlInfo model.UrlInfo) model.UrlInfo {
 	urlInfo.Success = false
-	parsed, _ := url.Parse(urlInfo.Url)
-	parts := strings.Split(parsed.Path, "/")
-	var uri string
-	if len(parts) == 4 {
-		uri = fmt.Sprintf("https://%s/api/v6/channels/detailsunion/%s", parsed.Host, parts[2])
-	} else {
-		locale := strings.Split(parts[1], "-")
-		uri = fmt.Sprintf("https://%s/api/v6/channels/detailsunion/%s?country=%s&language=%s", parsed.Host, parts[3], locale[1], locale[0])
+	p, err := c.profileSet().Match(urlInfo.Url)
+	if err != nil {
+		urlInfo.LastError = err.Error()
+		return urlInfo
+	}
+	uri := urlInfo.Url
+	if parsed, err := rokuurl.Parse(uri); err == nil {
+		uri = parsed.Canonical()
+	}
+	err = c.allow(ctx, uri)
+	if err != nil {
+		urlInfo.LastError = err.Error()
+		return urlInfo
+	}
+	result, err := c.htmlExtractor.Extract(ctx, uri, p)
+	if errors.Is(err, cache.ErrNotModified) {
+		urlInfo.Unchanged = true
+		err = nil
 	}
-	response, err := http.Get(uri)
 	if err != nil {
-		fmt.Printf("URL error %+v\n", uri)
 		urlInfo.LastError = err.Error()
 		return urlInfo
 	}
-	if response.StatusCode != 200 {
-		fmt.Printf("URL response != 200 %+v\n", uri)
-		urlInfo.LastError = fmt.Sprintf("unable to get API information: %s", response.Status)
+	urlInfo.AppName = result.Name
+	urlInfo.Rating = result.Rating
+	urlInfo.RatingCount = result.RatingCount
+	urlInfo.Success = true
+	return urlInfo
+}
+
+// doAPI get the channel information from the Roku API
+func (c *crawlerService) doAPI(ctx context.Context, urlInfo model.UrlInfo) model.UrlInfo {
+	urlInfo.Success = false
+	parsed, err := rokuurl.Parse(urlInfo.Url)
+	if err != nil {
+		urlInfo.LastError = err.Error()
 		return urlInfo
 	}
-	body, err := io.ReadAll(response.Body)
+	channel := roku.Channel{
+		Host:     rokuurl.Host,
+		ID:       parsed.ChannelID,
+		Country:  parsed.Country,
+		Language: parsed.Language,
+	}
+	err = c.allow(ctx, roku.DetailsURL(channel))
 	if err != nil {
 		urlInfo.LastError = err.Error()
 		return urlInfo
 	}
-	var apiResponse map[string]interface{}
-	err = json.Unmarshal(body, &apiResponse)
+	details, err := c.apiClient.Details(ctx, channel)
+	if errors.Is(err, cache.ErrNotModified) {
+		urlInfo.Unchanged = true
+		err = nil
+	}
 	if err != nil {
+		logging.FromContext(ctx).Warn("API error", "details_url", roku.DetailsURL(channel), "error", err)
 		urlInfo.LastError = err.Error()
 		return urlInfo
 	}
-	feed := apiResponse["feedChannel"].(map[string]interface{})
-	rating := (feed["starRating"].(float64) * 5) / 100
-	urlInfo.AppName = feed["name"].(string)
-	urlInfo.Rating = math.Round(rating*100) / 100
-	if feed["starRatingCount"] != nil {
-		urlInfo.RatingCount = int(feed["starRatingCount"].(float64))
+	feed := details.FeedChannel
+	urlInfo.AppName = feed.Name
+	urlInfo.Rating = feed.Rating()
+	urlInfo.RatingCount = int(feed.StarRatingCount.Value)
+	urlInfo.Details = model.Details{
+		Developer:   feed.Developer,
+		Category:    feed.Category(),
+		Price:       feed.Price.Value,
+		Description: feed.Description,
+		ReleaseDate: feed.Released(),
 	}
+	urlInfo.Success = true
 	return urlInfo
 }
 
-// connectToNats connect to NATS server
-func connectToNats(conf config.AppConfig) (*nats.Conn, error) {
-	opts := nats.Options{
-		Url:            conf.Nats.Host,
-		AllowReconnect: conf.Nats.AllowReconnect,
-		MaxReconnect:   conf.Nats.MaxReconnectAttempts,
-		ReconnectWait:  conf.Nats.ReconnectWait,
-		Timeout:        conf.Nats.Timeout,
+// allow check the URL against the host robots.txt and wait for the host rate limit
+func (c *crawlerService) allow(ctx context.Context, uri string) error {
+	if c.robots != nil {
+		err := c.robots.Allowed(ctx, uri)
+		if errors.Is(err, robots.ErrDisallowed) {
+			c.telemetry.Inc(telemetry.Disallowed)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", model.ErrorClassRobots, err)
+		}
 	}
-
-	nc, err := opts.Connect()
+	parsed, err := url.Parse(uri)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	log.Println("Connected to NATS at:", nc.ConnectedUrl())
-	return nc, nil
+	return c.limiter.Wait(ctx, parsed.Host)
 }
@@ -1 +1,276 @@
-package crawler
\ No newline at end of file
+package crawler
+
+import (
+	"context"
+	"errors"
+	"github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cache"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cancellation"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/extractor"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/model"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/profile"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/ratelimit"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/roku"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/telemetry"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const channelURL = "https://channelstore.roku.com/details/12345/channel"
+
+// fakeExtractor returns the result and error
+type fakeExtractor struct {
+	result *extractor.Result
+	err    error
+}
+
+func (f fakeExtractor) Extract(context.Context, string, *profile.Profile) (*extractor.Result, error) {
+	return f.result, f.err
+}
+
+// fakeAPIClient returns the details and error
+type fakeAPIClient struct {
+	details *roku.DetailsResponse
+	err     error
+}
+
+func (f fakeAPIClient) Details(context.Context, roku.Channel) (*roku.DetailsResponse, error) {
+	return f.details, f.err
+}
+
+// panickingExtractor panics for the URLs ending with /panic, and returns the result for the rest
+type panickingExtractor struct {
+	result *extractor.Result
+}
+
+func (p panickingExtractor) Extract(_ context.Context, url string, _ *profile.Profile) (*extractor.Result, error) {
+	if strings.HasSuffix(url, "/panic") {
+		panic("unexpected page")
+	}
+	return p.result, nil
+}
+
+// testProfiles returns a profile set for the channel store
+func testProfiles(t *testing.T) *profile.Set {
+	profiles, err := profile.NewSet([]profile.Profile{{
+		Name:   "default",
+		Domain: "channelstore.roku.com",
+		Fields: profile.Fields{
+			Name:   profile.Field{Selector: "h1"},
+			Rating: profile.Field{Selector: ".rating"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profiles
+}
+
+func TestCollectPanic(t *testing.T) {
+	c := &crawlerService{
+		mode:          extractorHTML,
+		registry:      cancellation.NewRegistry(),
+		profiles:      testProfiles(t),
+		htmlExtractor: panickingExtractor{result: &extractor.Result{Name: "Channel", Rating: 4.5}},
+		limiter:       ratelimit.NewLimiter(0),
+		telemetry:     telemetry.NewTelemetry(nil, "", 0),
+	}
+	messages := make(chan model.UrlInfo)
+	stop := make(chan struct{})
+	defer close(stop)
+	results := c.collect(context.Background(), 1, messages, stop, nil)
+
+	tests := []struct {
+		name        string
+		url         string
+		wantSuccess bool
+		wantError   string
+	}{
+		{name: "panic recovered", url: "https://channelstore.roku.com/details/1/panic", wantError: "panic: unexpected page"},
+		{name: "next message processed", url: channelURL, wantSuccess: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages <- model.UrlInfo{RequestID: tt.name, Url: tt.url}
+			got := <-results
+			if got.Success != tt.wantSuccess || got.LastError != tt.wantError {
+				t.Errorf("collect() = success %v error %q, want success %v error %q",
+					got.Success, got.LastError, tt.wantSuccess, tt.wantError)
+			}
+		})
+	}
+	counters := c.telemetry.Snapshot()
+	if counters[telemetry.Panics] != 1 || counters[telemetry.Processed] != 2 || counters[telemetry.Failed] != 1 {
+		t.Errorf("telemetry = %v, want 1 panic, 2 processed and 1 failed", counters)
+	}
+}
+
+func TestDoHTML(t *testing.T) {
+	profiles := testProfiles(t)
+	result := &extractor.Result{Name: "Channel", Rating: 4.5, RatingCount: 120}
+	tests := []struct {
+		name          string
+		err           error
+		wantUnchanged bool
+	}{
+		{name: "extracted"},
+		{name: "not modified", err: cache.ErrNotModified, wantUnchanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &crawlerService{
+				profiles:      profiles,
+				htmlExtractor: fakeExtractor{result: result, err: tt.err},
+				limiter:       ratelimit.NewLimiter(0),
+			}
+			got := c.doHTML(context.Background(), model.UrlInfo{Url: channelURL})
+			if !got.Success || got.Unchanged != tt.wantUnchanged || got.AppName != result.Name ||
+				got.Rating != result.Rating || got.RatingCount != result.RatingCount {
+				t.Errorf("doHTML() = %+v, want %+v unchanged %v", got, result, tt.wantUnchanged)
+			}
+		})
+	}
+}
+
+func TestDoAPI(t *testing.T) {
+	details := &roku.DetailsResponse{FeedChannel: &roku.FeedChannel{
+		Name:            "Channel",
+		StarRating:      roku.Number{Value: 90, Valid: true},
+		StarRatingCount: roku.Number{Value: 120, Valid: true},
+	}}
+	tests := []struct {
+		name          string
+		err           error
+		wantUnchanged bool
+	}{
+		{name: "requested"},
+		{name: "not modified", err: cache.ErrNotModified, wantUnchanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &crawlerService{
+				apiClient: fakeAPIClient{details: details, err: tt.err},
+				limiter:   ratelimit.NewLimiter(0),
+			}
+			got := c.doAPI(context.Background(), model.UrlInfo{Url: channelURL})
+			if !got.Success || got.Unchanged != tt.wantUnchanged || got.AppName != "Channel" ||
+				got.Rating != details.FeedChannel.Rating() || got.RatingCount != 120 {
+				t.Errorf("doAPI() = %+v, want unchanged %v", got, tt.wantUnchanged)
+			}
+		})
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		extractor string
+		file      string
+		wantErr   bool
+		wantNil   bool
+	}{
+		{name: "api extractor without profiles", extractor: extractorAPI, file: "missing.yaml", wantNil: true},
+		{name: "html extractor", extractor: extractorHTML, file: "../../config/profiles.yaml"},
+		{name: "html extractor without profiles", extractor: extractorHTML, file: "missing.yaml", wantErr: true},
+		{name: "chromedp extractor without profiles", extractor: extractorChromedp, file: "missing.yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := loadProfiles(tt.extractor, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (profiles == nil) != tt.wantNil {
+				t.Errorf("loadProfiles() = %v, want nil %v", profiles, tt.wantNil)
+			}
+		})
+	}
+}
+
+// countingTransport counts the requests, answering them with the status or the error
+type countingTransport struct {
+	requests int
+	status   int
+	err      error
+}
+
+func (t *countingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.requests++
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{StatusCode: t.status, Body: http.NoBody}, nil
+}
+
+func TestCheckStore(t *testing.T) {
+	tests := []struct {
+		name string
+		// age of the last check result, zero if the store was not checked
+		age          time.Duration
+		err          error
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "first check", wantRequests: 1},
+		{name: "first check failed", err: errors.New("connection refused"), wantRequests: 1, wantErr: true},
+		{name: "last result reused", age: time.Second},
+		{name: "last result expired", age: storeCheckInterval + time.Second, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &countingTransport{status: http.StatusForbidden, err: tt.err}
+			c := &crawlerService{httpClient: &http.Client{Transport: transport}}
+			if tt.age != 0 {
+				c.storeCheck.checkedAt = time.Now().Add(-tt.age)
+			}
+			err := c.checkStore(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// the result is reused by the next probe
+			if again := c.checkStore(context.Background()); (again != nil) != tt.wantErr {
+				t.Errorf("second checkStore() error = %v, wantErr %v", again, tt.wantErr)
+			}
+			if transport.requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", transport.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	var current config.AppConfig
+	current.Crawler.Extractor = extractorAPI
+	current.Crawler.Collectors = 2
+	current.Crawler.Deadline = time.Second
+	current.Crawler.HTTP.Timeout = time.Second
+	c := &crawlerService{
+		mode:      extractorAPI,
+		conf:      current,
+		settings:  current.Crawler,
+		limiter:   ratelimit.NewLimiter(0),
+		telemetry: telemetry.NewTelemetry(nil, "", 0),
+	}
+
+	next := current
+	next.Crawler.Deadline = 2 * time.Second
+	next.Crawler.HTTP.Timeout = 2 * time.Second
+	if !restartRequired(c.conf, next) {
+		t.Fatal("restartRequired() = false, want true for an HTTP timeout change")
+	}
+	if err := c.apply(next); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if got := c.currentSettings().Deadline; got != next.Crawler.Deadline {
+		t.Errorf("deadline = %s, want %s", got, next.Crawler.Deadline)
+	}
+	if got := c.telemetry.Snapshot()[telemetry.ConfigChanges]; got != 1 {
+		t.Errorf("config changes = %d, want 1", got)
+	}
+	// the applied configuration is the one the next reloads are compared with
+	if restartRequired(c.conf, next) {
+		t.Error("restartRequired() = true after applying the configuration, want false")
+	}
+}
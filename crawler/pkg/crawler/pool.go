@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"runtime"
+	"sync"
+)
+
+// collectorPool resizable set of collectors, the removed collectors finish their in-flight work before exiting
+type collectorPool struct {
+	mu      sync.Mutex
+	size    int
+	nextID  int
+	quits   []chan struct{}
+	start   func(id int, quit <-chan struct{})
+	stopped bool
+}
+
+// collectorsCount returns the number of collectors to run, the number of CPUs if it is not set
+func collectorsCount(n int) int {
+	if n <= 0 {
+		return runtime.NumCPU()
+	}
+	return n
+}
+
+// Start run the collectors with the start function, it is expected to return once the collector is running.
+// A collector should exit when its quit channel is closed
+func (p *collectorPool) Start(start func(id int, quit <-chan struct{})) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.start = start
+	p.resize()
+}
+
+// Resize set the number of collectors, they are started or stopped if the pool is running
+func (p *collectorPool) Resize(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.size = size
+	p.resize()
+}
+
+// Stop prevent new collectors from being started, the running ones are stopped by the caller
+func (p *collectorPool) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+}
+
+func (p *collectorPool) resize() {
+	if p.start == nil || p.stopped {
+		return
+	}
+	for len(p.quits) < p.size {
+		quit := make(chan struct{})
+		p.quits = append(p.quits, quit)
+		p.start(p.nextID, quit)
+		p.nextID++
+	}
+	for len(p.quits) > p.size {
+		last := len(p.quits) - 1
+		close(p.quits[last])
+		p.quits = p.quits[:last]
+	}
+}
@@ -0,0 +1,90 @@
+package crawler
+
+import (
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/profile"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/telemetry"
+	"reflect"
+)
+
+// Reload load the configuration and apply its tunable settings to the running crawler: the number of collectors,
+// the host rate limit, the deadline, the shutdown grace and the extraction profiles. The in-flight work is not
+// interrupted, the removed collectors finish their current URL. Invalid configurations are rejected as a whole
+func (c *crawlerService) Reload(load func() (config.AppConfig, error)) {
+	conf, err := load()
+	if err == nil {
+		err = c.apply(conf)
+	}
+	if err != nil {
+		logging.L().Error("configuration update rejected", "error", err)
+		c.telemetry.Inc(telemetry.ConfigRejected)
+	}
+}
+
+func (c *crawlerService) apply(conf config.AppConfig) error {
+	profiles, err := loadProfiles(c.mode, conf.Crawler.Profiles)
+	if err != nil {
+		return err
+	}
+
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	if restartRequired(c.conf, conf) {
+		logging.L().Warn("configuration changes other than the crawler collectors, rate limit, deadline, " +
+			"shutdown grace and profiles, such as the HTTP client timeouts, require a restart")
+	}
+	next := conf.Crawler
+	if from, to := collectorsCount(c.settings.Collectors), collectorsCount(next.Collectors); from != to {
+		c.collectors.Resize(to)
+		c.changed("crawler.collectors", from, to)
+	}
+	if from, to := c.settings.RateLimit.HostDelay, next.RateLimit.HostDelay; from != to {
+		c.limiter.SetDelay(to)
+		c.changed("crawler.rate_limit.host_delay", from, to)
+	}
+	if from, to := c.settings.Deadline, next.Deadline; from != to {
+		c.changed("crawler.deadline", from, to)
+	}
+	if from, to := c.settings.ShutdownGrace, next.ShutdownGrace; from != to {
+		c.changed("crawler.shutdown_grace", from, to)
+	}
+	if from, to := c.settings.Profiles, next.Profiles; profiles != nil && (from != to || !profiles.Equal(c.profiles)) {
+		c.profiles = profiles
+		c.changed("crawler.profiles", from, to)
+	}
+	c.settings = next
+	// the next changes are compared with this configuration, so the restart warning is logged once per change
+	c.conf = conf
+	return nil
+}
+
+// changed log and count an applied change
+func (c *crawlerService) changed(key string, from, to interface{}) {
+	logging.L().Info("configuration change applied", "key", key, "from", from, "to", to)
+	c.telemetry.Inc(telemetry.ConfigChanges)
+}
+
+// currentSettings returns the crawler settings, the tunable ones can be changed by Reload
+func (c *crawlerService) currentSettings() config.Crawler {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.settings
+}
+
+// profileSet returns the extraction profiles, they can be changed by Reload
+func (c *crawlerService) profileSet() *profile.Set {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.profiles
+}
+
+// restartRequired reports whether the new configuration changes settings that are only applied on start
+func restartRequired(current, next config.AppConfig) bool {
+	next.Crawler.Collectors = current.Crawler.Collectors
+	next.Crawler.RateLimit.HostDelay = current.Crawler.RateLimit.HostDelay
+	next.Crawler.Deadline = current.Crawler.Deadline
+	next.Crawler.ShutdownGrace = current.Crawler.ShutdownGrace
+	next.Crawler.Profiles = current.Crawler.Profiles
+	return !reflect.DeepEqual(current, next)
+}
@@ -0,0 +1,110 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cache"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/profile"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const channelPage = `<html><head><script type="application/ld+json">
+{"name": "Channel", "aggregateRating": {"ratingValue": "4.5", "ratingCount": 120}}
+</script></head><body></body></html>`
+
+func TestParseJSONLD(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		// scale of the rating field
+		scale float64
+		want  Result
+	}{
+		{
+			name: "rating without scale",
+			page: channelPage,
+			want: Result{Name: "Channel", Rating: 4.5, RatingCount: 120},
+		},
+		{
+			name:  "rating scaled and rounded",
+			page:  strings.Replace(channelPage, `"4.5"`, `83.3333`, 1),
+			scale: 0.05,
+			want:  Result{Name: "Channel", Rating: 4.17, RatingCount: 120},
+		},
+		{
+			name: "fractional count rounded",
+			page: strings.Replace(channelPage, `120`, `"119.6"`, 1),
+			want: Result{Name: "Channel", Rating: 4.5, RatingCount: 120},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &profile.Profile{Fields: profile.Fields{Rating: profile.Field{Scale: tt.scale}}}
+			got, err := Parse(strings.NewReader(tt.page), p)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(channelPage))
+	}))
+	defer server.Close()
+	want := Result{Name: "Channel", Rating: 4.5, RatingCount: 120}
+
+	tests := []struct {
+		name string
+		// cached validators before the extraction, if any
+		cached  *cache.Validators
+		wantErr error
+	}{
+		{name: "not cached"},
+		{name: "cached", cached: &cache.Validators{ETag: `"v1"`, Data: []byte(`{"name":"Channel","rating":4.5,"rating_count":120}`)},
+			wantErr: cache.ErrNotModified},
+		{name: "cached without data", cached: &cache.Validators{ETag: `"v1"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cache.NewDiskCache(t.TempDir(), 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.cached != nil {
+				if err := c.Put(server.URL, *tt.cached); err != nil {
+					t.Fatal(err)
+				}
+			}
+			e := NewHTMLExtractor(server.Client(), c)
+			got, err := e.Extract(context.Background(), server.URL, &profile.Profile{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Extract() error = %v, want %v", err, tt.wantErr)
+			}
+			if got == nil || *got != want {
+				t.Fatalf("Extract() = %+v, want %+v", got, want)
+			}
+
+			// the result is cached, so the next extraction is not modified
+			got, err = e.Extract(context.Background(), server.URL, &profile.Profile{})
+			if !errors.Is(err, cache.ErrNotModified) {
+				t.Fatalf("second Extract() error = %v, want %v", err, cache.ErrNotModified)
+			}
+			if got == nil || *got != want {
+				t.Errorf("second Extract() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cache"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/profile"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/selector"
+	"golang.org/x/net/html"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound the page does not contain the expected fields
+var ErrNotFound = errors.New("rating information not found in page")
+
+// Result information extracted from a channel page
+type Result struct {
+	Name        string  `json:"name"`
+	Rating      float64 `json:"rating"`
+	RatingCount int     `json:"rating_count"`
+}
+
+// HTMLExtractor interface
+type HTMLExtractor interface {
+	Extract(ctx context.Context, uri string, p *profile.Profile) (*Result, error)
+}
+
+type htmlExtractor struct {
+	client *http.Client
+	cache  cache.Cache
+}
+
+// NewHTMLExtractor returns an extractor that gets the information from the server rendered HTML,
+// without running a browser. Responses validators are kept in the cache to send conditional requests
+func NewHTMLExtractor(client *http.Client, c cache.Cache) HTMLExtractor {
+	return &htmlExtractor{
+		client: client,
+		cache:  c,
+	}
+}
+
+// Extract fetch the page and extract the fields from JSON-LD scripts and the profile selectors.
+// cache.ErrNotModified is returned with the cached result if the page did not change since the last successful
+// extraction
+func (e *htmlExtractor) Extract(ctx context.Context, uri string, p *profile.Profile) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, cached, err := cache.Do(e.client, e.cache, uri, req)
+	if errors.Is(err, cache.ErrNotModified) {
+		var result Result
+		if decodeErr := cached.Decode(&result); decodeErr != nil {
+			return nil, fmt.Errorf("unable to decode cached result: %w", decodeErr)
+		}
+		return &result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get page: %s", resp.Status)
+	}
+	result, err := Parse(resp.Body, p)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := cache.FromResponse(resp).WithData(result)
+	if err == nil {
+		err = e.cache.Put(uri, validators)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("unable to cache response validators", "error", err)
+	}
+	return result, nil
+}
+
+// Parse extract the fields from an HTML document. JSON-LD values take precedence over the profile selectors, the
+// profile rating scale is applied to them as well
+func Parse(r io.Reader, p *profile.Profile) (*Result, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if ld := findJSONLD(doc); ld != nil && ld.Name != "" {
+		if rating, ok := toFloat(ld.AggregateRating.RatingValue); ok {
+			count, _ := toFloat(ld.AggregateRating.RatingCount)
+			return &Result{
+				Name:        ld.Name,
+				Rating:      p.Fields.Rating.Scaled(rating),
+				RatingCount: int(math.Round(p.Fields.RatingCount.Scaled(count))),
+			}, nil
+		}
+	}
+
+	name := find(doc, p.Fields.Name)
+	rating := find(doc, p.Fields.Rating)
+	ratingCount := find(doc, p.Fields.RatingCount)
+	if name == "" || rating == "" {
+		return nil, ErrNotFound
+	}
+	return FromValues(p, name, rating, ratingCount)
+}
+
+// FromValues post-process the raw values read with the profile selectors
+func FromValues(p *profile.Profile, name string, rating string, ratingCount string) (*Result, error) {
+	result := Result{
+		Name: p.Fields.Name.Text(name),
+	}
+	var err error
+	result.Rating, err = p.Fields.Rating.Number(rating)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(ratingCount) != "" {
+		count, err := p.Fields.RatingCount.Number(ratingCount)
+		if err != nil {
+			return nil, err
+		}
+		result.RatingCount = int(math.Round(count))
+	}
+	return &result, nil
+}
+
+type jsonLD struct {
+	Name            string `json:"name"`
+	AggregateRating *struct {
+		RatingValue interface{} `json:"ratingValue"`
+		RatingCount interface{} `json:"ratingCount"`
+	} `json:"aggregateRating"`
+}
+
+// findJSONLD returns the first JSON-LD object having a name and rating information
+func findJSONLD(root *html.Node) *jsonLD {
+	var found *jsonLD
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if found != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "script" {
+			if t, _ := selector.Attribute(n, "type"); t == "application/ld+json" && n.FirstChild != nil {
+				found = decodeJSONLD(n.FirstChild.Data)
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// decodeJSONLD decode a JSON-LD script, which could be a single object or a list of them
+func decodeJSONLD(data string) *jsonLD {
+	var single jsonLD
+	if err := json.Unmarshal([]byte(data), &single); err == nil {
+		if single.AggregateRating != nil {
+			return &single
+		}
+		return nil
+	}
+	var list []jsonLD
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		return nil
+	}
+	for i := range list {
+		if list[i].AggregateRating != nil {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// find returns the value of the first node matching the field selector, or an empty string
+func find(doc *html.Node, f profile.Field) string {
+	sel := f.Compiled()
+	if sel == nil {
+		return ""
+	}
+	n := sel.Find(doc)
+	if n == nil {
+		return ""
+	}
+	return value(n, f)
+}
+
+// value returns the field attribute of the node if it is defined, otherwise its text
+func value(n *html.Node, f profile.Field) string {
+	if f.Attribute != "" {
+		attr, _ := selector.Attribute(n, f.Attribute)
+		return strings.TrimSpace(attr)
+	}
+	return text(n)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// text returns the text content of the node and its children
+func text(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
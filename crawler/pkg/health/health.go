@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/crawler/config"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Check returns an error if the dependency is not reachable
+type Check func(ctx context.Context) error
+
+// Server HTTP server exposing the liveness (/healthz) and readiness (/readyz) probes
+type Server interface {
+	Start() error
+	Shutdown(ctx context.Context) error
+}
+
+type server struct {
+	httpServer *http.Server
+	timeout    time.Duration
+	checks     map[string]Check
+}
+
+// status response of the probes
+type status struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// NewServer returns a health server running the readiness checks, each of them limited by the configured timeout
+func NewServer(conf config.Health, checks map[string]Check) Server {
+	s := &server{
+		timeout: conf.Timeout,
+		checks:  checks,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.healthz)
+	mux.HandleFunc("/readyz", s.readyz)
+	s.httpServer = &http.Server{
+		Addr:    conf.Address,
+		Handler: mux,
+	}
+	return s
+}
+
+// Start serve the probes until the server is shut down
+func (s *server) Start() error {
+	logging.L().Info("starting health server", "address", s.httpServer.Addr)
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stop the server
+func (s *server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// healthz the process is alive and serving requests
+func (s *server) healthz(w http.ResponseWriter, _ *http.Request) {
+	write(w, http.StatusOK, status{Status: "ok"})
+}
+
+// readyz run the checks concurrently, the service is ready if all of them succeed
+func (s *server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	result := status{Status: "ok", Checks: map[string]string{}}
+	for name, check := range s.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			err := check(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Status = "unavailable"
+				result.Checks[name] = err.Error()
+				return
+			}
+			result.Checks[name] = "ok"
+		}(name, check)
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	if result.Status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+	write(w, code, result)
+}
+
+func write(w http.ResponseWriter, code int, body status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/crawler/config"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// NewClient returns the HTTP client shared by the extractors
+func NewClient(conf config.HTTPClient) (*http.Client, error) {
+	proxy, err := rotatingProxy(conf.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout: conf.Timeout,
+		}).DialContext,
+		ForceAttemptHTTP2:   conf.HTTP2,
+		MaxIdleConns:        conf.MaxIdleConns,
+		MaxIdleConnsPerHost: conf.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     conf.MaxConnsPerHost,
+		IdleConnTimeout:     conf.IdleConnTimeout,
+		TLSHandshakeTimeout: conf.Timeout,
+		// the transport requests gzip and decompress the responses transparently
+		DisableCompression: !conf.Gzip,
+	}
+	if !conf.HTTP2 {
+		// a non-nil empty map disables HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return &http.Client{
+		Transport: &userAgentTransport{
+			next:       transport,
+			userAgents: conf.UserAgents,
+		},
+		Timeout: conf.Timeout,
+	}, nil
+}
+
+// rotatingProxy returns a proxy function that rotates over the proxy list. The environment proxy configuration
+// (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) is used if the list is empty
+func rotatingProxy(proxies []string) (func(*http.Request) (*url.URL, error), error) {
+	if len(proxies) == 0 {
+		return http.ProxyFromEnvironment, nil
+	}
+	urls := make([]*url.URL, len(proxies))
+	for i, proxy := range proxies {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", proxy)
+		}
+		urls[i] = u
+	}
+	var next uint64
+	return func(*http.Request) (*url.URL, error) {
+		n := atomic.AddUint64(&next, 1)
+		return urls[(n-1)%uint64(len(urls))], nil
+	}, nil
+}
+
+// userAgentTransport set the User-Agent header rotating over the configured list
+type userAgentTransport struct {
+	count      uint64
+	next       http.RoundTripper
+	userAgents []string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.userAgents) == 0 || req.Header.Get("User-Agent") != "" {
+		return t.next.RoundTrip(req)
+	}
+	n := atomic.AddUint64(&t.count, 1)
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgents[(n-1)%uint64(len(t.userAgents))])
+	return t.next.RoundTrip(req)
+}
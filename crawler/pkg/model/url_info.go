@@ -2,21 +2,51 @@ package model
 
 import "time"
 
+// Error classes used as LastError prefix
+const (
+	// ErrorClassPanic the collector recovered from a panic
+	ErrorClassPanic = "panic"
+	// ErrorClassRobots the URL is disallowed by robots.txt or the rules could not be fetched
+	ErrorClassRobots = "robots"
+	// ErrorClassCancelled the request was cancelled by a client
+	ErrorClassCancelled = "cancelled"
+)
+
 type UrlInfo struct {
-	RequestID   string  `json:"request_id"`
-	Url         string  `json:"url"`
-	AppName     string  `json:"app_name"`
-	Rating      float64 `json:"rating"`
-	RatingCount int     `json:"rating_count"`
-	Success     bool    `json:"success"`
-	LastError   string  `json:"last_error"`
-	Stats       Stats   `json:"stats"`
+	RequestID string `json:"request_id"`
+	JobID     string `json:"job_id,omitempty"` // submission the URL belongs to, i.e.: a reader run
+	// Client that queued the URL, the only one allowed to cancel it. It is not stored
+	Client string `json:"-"`
+	// QueuedAt time the listener queued the URL, zero if it is unknown. It is not stored
+	QueuedAt    time.Time `json:"-"`
+	Url         string    `json:"url"`
+	AppName     string    `json:"app_name"`
+	Rating      float64   `json:"rating"`
+	RatingCount int       `json:"rating_count"`
+	Success     bool      `json:"success"`
+	// Unchanged the upstream response did not change since the last crawl, the information is the cached one
+	Unchanged bool   `json:"unchanged"`
+	LastError string `json:"last_error"`
+	Stats     Stats  `json:"stats"`
+	Details
+}
+
+// Details extended channel information, only available from the API extractor
+type Details struct {
+	Developer   string     `json:"developer"`
+	Category    string     `json:"category"`
+	Price       float64    `json:"price"`
+	Description string     `json:"description"`
+	ReleaseDate *time.Time `json:"release_date"`
 }
 
 type Stats struct {
 	CollectorID int   `json:"collector_id"`
 	Waiting     Times `json:"waiting"`
 	Collector   Times `json:"collector"`
+	// Deadline time in milliseconds allowed to process the URL
+	Deadline         int64 `json:"deadline"`
+	DeadlineExceeded bool  `json:"deadline_exceeded"`
 }
 
 type Times struct {
@@ -0,0 +1,233 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/selector"
+	"github.com/spf13/viper"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoProfile there is no extraction profile for the URL
+var ErrNoProfile = errors.New("no extraction profile matches the URL")
+
+var numberRegexp = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+// localeSeparators decimal and group separators used by each locale
+var localeSeparators = map[string][2]string{
+	"en": {".", ","},
+	"de": {",", "."},
+	"es": {",", "."},
+	"it": {",", "."},
+	"pt": {",", "."},
+	"fr": {",", " "},
+}
+
+// Profile extraction profile for the pages of a domain
+type Profile struct {
+	Name string `mapstructure:"name"`
+	// Domain host of the URLs, subdomains are matched as well
+	Domain string `mapstructure:"domain"`
+	// Pattern optional regular expression the full URL should match
+	Pattern string `mapstructure:"pattern"`
+	// WaitSelector selector of an element to wait for before reading the fields (chromedp extractor only)
+	WaitSelector string `mapstructure:"wait_selector"`
+	Fields       Fields `mapstructure:"fields"`
+
+	pattern *regexp.Regexp
+}
+
+// Fields field definitions of a profile
+type Fields struct {
+	Name        Field `mapstructure:"name"`
+	Rating      Field `mapstructure:"rating"`
+	RatingCount Field `mapstructure:"rating_count"`
+}
+
+// Field how to get and post-process a field value
+type Field struct {
+	Selector string `mapstructure:"selector"`
+	// Attribute read the attribute value instead of the element text
+	Attribute string `mapstructure:"attribute"`
+	// Regex keep only the first capture group (or the whole match if there are no groups)
+	Regex string `mapstructure:"regex"`
+	// Locale define the decimal and group separators used to parse numbers, i.e.: en => 1,234.5, de => 1.234,5
+	Locale string `mapstructure:"locale"`
+	// Scale factor applied to number values, i.e.: 0.05 to convert a 0-100 rating into a 0-5 one
+	Scale float64 `mapstructure:"scale"`
+
+	regex    *regexp.Regexp
+	compiled *selector.Selector
+}
+
+// Set list of profiles, the first matching profile is used
+type Set struct {
+	profiles []Profile
+}
+
+// Load read the profiles from a YAML file
+func Load(file string) (*Set, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	v.SetConfigType("yaml")
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []Profile
+	err = v.UnmarshalKey("profiles", &profiles)
+	if err != nil {
+		return nil, err
+	}
+	return NewSet(profiles)
+}
+
+// NewSet validate and compile the profiles
+func NewSet(profiles []Profile) (*Set, error) {
+	for i := range profiles {
+		err := profiles[i].compile()
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profiles[i].Name, err)
+		}
+	}
+	return &Set{profiles: profiles}, nil
+}
+
+// Match returns the first profile for the URL
+func (s *Set) Match(uri string) (*Profile, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for i := range s.profiles {
+		p := &s.profiles[i]
+		if p.Domain != "" && host != p.Domain && !strings.HasSuffix(host, "."+p.Domain) {
+			continue
+		}
+		if p.pattern != nil && !p.pattern.MatchString(uri) {
+			continue
+		}
+		return p, nil
+	}
+	return nil, ErrNoProfile
+}
+
+// Equal reports whether both sets have the same profiles, in the same order
+func (s *Set) Equal(other *Set) bool {
+	if len(s.profiles) != len(other.profiles) {
+		return false
+	}
+	for i := range s.profiles {
+		if s.profiles[i].definition() != other.profiles[i].definition() {
+			return false
+		}
+	}
+	return true
+}
+
+// definition returns the profile without the compiled expressions
+func (p Profile) definition() Profile {
+	p.pattern = nil
+	for _, f := range []*Field{&p.Fields.Name, &p.Fields.Rating, &p.Fields.RatingCount} {
+		f.regex = nil
+		f.compiled = nil
+	}
+	return p
+}
+
+func (p *Profile) compile() error {
+	var err error
+	p.Domain = strings.ToLower(p.Domain)
+	if p.Pattern != "" {
+		p.pattern, err = regexp.Compile(p.Pattern)
+		if err != nil {
+			return err
+		}
+	}
+	if p.Fields.Name.Selector == "" || p.Fields.Rating.Selector == "" {
+		return errors.New("name and rating selectors are required")
+	}
+	for _, f := range []*Field{&p.Fields.Name, &p.Fields.Rating, &p.Fields.RatingCount} {
+		err = f.compile()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Field) compile() error {
+	var err error
+	if f.Selector != "" {
+		f.compiled, err = selector.Parse(f.Selector)
+		if err != nil {
+			return err
+		}
+	}
+	if f.Regex != "" {
+		f.regex, err = regexp.Compile(f.Regex)
+		if err != nil {
+			return err
+		}
+	}
+	if _, ok := localeSeparators[f.Locale]; f.Locale != "" && !ok {
+		return fmt.Errorf("unsupported locale: %s", f.Locale)
+	}
+	return nil
+}
+
+// Compiled returns the field selector used by the html extractor, nil if the field does not have one
+func (f Field) Compiled() *selector.Selector {
+	return f.compiled
+}
+
+// Text returns the raw value after applying the regex capture
+func (f Field) Text(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if f.regex == nil {
+		return raw
+	}
+	match := f.regex.FindStringSubmatch(raw)
+	switch len(match) {
+	case 0:
+		return ""
+	case 1:
+		return strings.TrimSpace(match[0])
+	default:
+		return strings.TrimSpace(match[1])
+	}
+}
+
+// Number parse the raw value as a number using the locale separators and the scale factor
+func (f Field) Number(raw string) (float64, error) {
+	text := f.Text(raw)
+	separators, ok := localeSeparators[f.Locale]
+	if !ok {
+		separators = localeSeparators["en"]
+	}
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	text = strings.ReplaceAll(text, separators[1], "")
+	text = strings.ReplaceAll(text, separators[0], ".")
+	match := numberRegexp.FindString(text)
+	if match == "" {
+		return 0, fmt.Errorf("unable to parse number from %q", raw)
+	}
+	n, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0, err
+	}
+	return f.Scaled(n), nil
+}
+
+// Scaled apply the scale factor to a number value rounded to two decimals, the value is kept if there is no scale
+func (f Field) Scaled(n float64) float64 {
+	if f.Scale == 0 {
+		return n
+	}
+	return math.Round(n*f.Scale*100) / 100
+}
@@ -0,0 +1,133 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "simple selectors",
+			yaml: `profiles:
+  - name: roku
+    domain: channelstore.roku.com
+    fields:
+      name:
+        selector: h1[itemprop="name"]
+      rating:
+        selector: "#rating"
+      rating_count:
+        selector: small.count
+`,
+		},
+		{
+			name: "descendant combinator",
+			yaml: `profiles:
+  - name: roku
+    fields:
+      name:
+        selector: div h1
+      rating:
+        selector: span.rating
+`,
+			wantErr: true,
+		},
+		{
+			name: "child combinator",
+			yaml: `profiles:
+  - name: roku
+    fields:
+      name:
+        selector: h1
+      rating:
+        selector: div > span
+`,
+			wantErr: true,
+		},
+		{
+			name: "optional field selector",
+			yaml: `profiles:
+  - name: roku
+    fields:
+      name:
+        selector: h1
+      rating:
+        selector: span
+      rating_count:
+        selector: "small:last-child"
+`,
+			wantErr: true,
+		},
+		{
+			name: "missing rating selector",
+			yaml: `profiles:
+  - name: roku
+    fields:
+      name:
+        selector: h1
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "profiles.yaml")
+			err := os.WriteFile(file, []byte(tt.yaml), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set, err := Load(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && set.profiles[0].Fields.Name.Compiled() == nil {
+				t.Errorf("Load() name selector not compiled")
+			}
+		})
+	}
+}
+
+func TestFieldNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		raw     string
+		want    float64
+		wantErr bool
+	}{
+		{name: "plain number", raw: "4.5", want: 4.5},
+		{name: "group separators", field: Field{Locale: "en"}, raw: "1,234,567", want: 1234567},
+		{name: "german locale", field: Field{Locale: "de"}, raw: "1.234,5", want: 1234.5},
+		{name: "french locale", field: Field{Locale: "fr"}, raw: "1 234,5", want: 1234.5},
+		{name: "surrounding text", raw: "Rated 4.2 stars", want: 4.2},
+		{name: "regex capture", field: Field{Regex: `([\d,]+)\s+ratings`}, raw: "4.2 1,234 ratings", want: 1234},
+		{name: "scale", field: Field{Scale: 0.05}, raw: "90", want: 4.5},
+		{name: "scale rounded to two decimals", field: Field{Scale: 0.05}, raw: "83.3333", want: 4.17},
+		{name: "scale rounded up", field: Field{Scale: 0.1}, raw: "44.96", want: 4.5},
+		{name: "scale with locale", field: Field{Locale: "de", Scale: 0.05}, raw: "83,3", want: 4.17},
+		{name: "negative number", raw: "-1.5", want: -1.5},
+		{name: "no number", raw: "no ratings", wantErr: true},
+		{name: "regex without match", field: Field{Regex: `(\d+) ratings`}, raw: "4.2 stars", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.field
+			if err := f.compile(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.Number(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Number() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Number() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
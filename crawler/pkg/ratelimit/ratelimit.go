@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter per host rate limiter, requests to the same host are spaced by the host delay
+type Limiter interface {
+	Wait(ctx context.Context, host string) error
+	SetDelay(delay time.Duration)
+	SetHostDelay(host string, delay time.Duration)
+}
+
+type limiter struct {
+	mu         sync.Mutex
+	delay      time.Duration
+	hostDelays map[string]time.Duration
+	next       map[string]time.Time
+}
+
+// NewLimiter returns a limiter using the delay for every host, unless a longer host delay is set
+func NewLimiter(delay time.Duration) Limiter {
+	return &limiter{
+		delay:      delay,
+		hostDelays: map[string]time.Duration{},
+		next:       map[string]time.Time{},
+	}
+}
+
+// Wait block until a request to the host is allowed or the context is done
+func (l *limiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	delay := l.delay
+	if hostDelay := l.hostDelays[host]; hostDelay > delay {
+		delay = hostDelay
+	}
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(delay)
+	l.mu.Unlock()
+
+	wait := at.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SetDelay set the default delay between requests to the same host
+func (l *limiter) SetDelay(delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.delay = delay
+}
+
+// SetHostDelay set the delay for a host, i.e.: from robots.txt Crawl-delay. The longest of the default and host
+// delays is used
+func (l *limiter) SetHostDelay(host string, delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hostDelays[host] = delay
+}
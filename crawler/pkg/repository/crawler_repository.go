@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/crawler/config"
 	"github.com/StevenRojas/natscrawler/crawler/pkg/model"
 	_ "github.com/lib/pq"
@@ -13,7 +14,10 @@ import (
 
 type Repository interface {
 	Close() error
+	Ping(ctx context.Context) error
+	Migrate(ctx context.Context) error
 	AddURL(ctx context.Context, urlInfo model.UrlInfo) error
+	Results(ctx context.Context, requestID string, limit int) ([]model.UrlInfo, error)
 }
 
 type repo struct {
@@ -39,7 +43,7 @@ func NewRepository(dbConf config.Database) (Repository, error) {
 		return nil, err
 	}
 
-	fmt.Println("Connected to database")
+	logging.L().Info("connected to database", "host", dbConf.Host, "database", dbConf.Database)
 	return &repo{db: db}, nil
 }
 
@@ -48,21 +52,86 @@ func (r *repo) Close() error {
 	return r.db.Close()
 }
 
-// AddURL add URL info to the DB
+// Ping check the DB connection
+func (r *repo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Migrate apply the schema migrations, all of them are idempotent
+func (r *repo) Migrate(ctx context.Context) error {
+	for _, statement := range migrations {
+		_, err := r.db.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+	logging.L().Info("database migrated")
+	return nil
+}
+
+// AddURL add URL info to the DB. A request ID can be crawled again, i.e.: the request IDs derived from the URLs, then
+// the stored result is replaced by the latest one, keeping the job if the latest one does not have it
 func (r *repo) AddURL(ctx context.Context, ui model.UrlInfo) error {
 	statement := `INSERT INTO public.ulrinfo(
-	request_id, url, app_name, rating, rating_count, success, last_error, stats, created_at)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9);`
+	request_id, url, app_name, rating, rating_count, success, last_error, stats, created_at,
+	developer, category, price, description, release_date, unchanged, job_id)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,NULLIF($16, ''))
+	ON CONFLICT (request_id) DO UPDATE SET
+	url = EXCLUDED.url, app_name = EXCLUDED.app_name, rating = EXCLUDED.rating,
+	rating_count = EXCLUDED.rating_count, success = EXCLUDED.success, last_error = EXCLUDED.last_error,
+	stats = EXCLUDED.stats, created_at = EXCLUDED.created_at, developer = EXCLUDED.developer,
+	category = EXCLUDED.category, price = EXCLUDED.price, description = EXCLUDED.description,
+	release_date = EXCLUDED.release_date, unchanged = EXCLUDED.unchanged,
+	job_id = COALESCE(EXCLUDED.job_id, ulrinfo.job_id);`
 
 	stats, _ := json.Marshal(ui.Stats)
-	stmt, err := r.db.PrepareContext(ctx, statement)
+	_, err := r.db.ExecContext(ctx, statement, ui.RequestID, ui.Url, ui.AppName, ui.Rating, ui.RatingCount, ui.Success,
+		ui.LastError, stats, time.Now().UTC(), ui.Developer, ui.Category, ui.Price, ui.Description, ui.ReleaseDate,
+		ui.Unchanged, ui.JobID)
+	return err
+}
+
+// Results returns the latest stored URLs, only the one of the request if the request ID is set
+func (r *repo) Results(ctx context.Context, requestID string, limit int) ([]model.UrlInfo, error) {
+	statement := `SELECT request_id, COALESCE(job_id, ''), url, app_name, rating, rating_count, success, last_error,
+	stats, unchanged, developer, category, price, description, release_date
+	FROM public.ulrinfo
+	WHERE $1 = '' OR request_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2;`
+
+	rows, err := r.db.QueryContext(ctx, statement, requestID, limit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = stmt.ExecContext(ctx, ui.RequestID, ui.Url, ui.AppName, ui.Rating, ui.RatingCount, ui.Success, ui.LastError, stats, time.Now().UTC())
-	if err != nil {
-		return err
+	defer rows.Close()
+	var results []model.UrlInfo
+	for rows.Next() {
+		var ui model.UrlInfo
+		var rating, price sql.NullFloat64
+		var ratingCount sql.NullInt64
+		var lastError, developer, category, description sql.NullString
+		var releaseDate sql.NullTime
+		var stats []byte
+		err = rows.Scan(&ui.RequestID, &ui.JobID, &ui.Url, &ui.AppName, &rating, &ratingCount, &ui.Success, &lastError,
+			&stats, &ui.Unchanged, &developer, &category, &price, &description, &releaseDate)
+		if err != nil {
+			return nil, err
+		}
+		ui.Rating = rating.Float64
+		ui.RatingCount = int(ratingCount.Int64)
+		ui.LastError = lastError.String
+		ui.Developer = developer.String
+		ui.Category = category.String
+		ui.Price = price.Float64
+		ui.Description = description.String
+		if releaseDate.Valid {
+			ui.ReleaseDate = &releaseDate.Time
+		}
+		if len(stats) > 0 {
+			_ = json.Unmarshal(stats, &ui.Stats)
+		}
+		results = append(results, ui)
 	}
-	fmt.Printf("App saved: %s\n", ui.AppName)
-	return nil
-}
\ No newline at end of file
+	return results, rows.Err()
+}
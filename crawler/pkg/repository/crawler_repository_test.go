@@ -1 +1,87 @@
 package repository
+
+import (
+	"context"
+	"github.com/StevenRojas/natscrawler/crawler/config"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/model"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testRepository connects to the database set by the CT_TEST_DATABASE_* variables, the test is skipped without it
+func testRepository(t *testing.T) Repository {
+	host := os.Getenv("CT_TEST_DATABASE_HOST")
+	if host == "" {
+		t.Skip("CT_TEST_DATABASE_HOST is not set")
+	}
+	port, err := strconv.Atoi(os.Getenv("CT_TEST_DATABASE_PORT"))
+	if err != nil {
+		port = 5432
+	}
+	r, err := NewRepository(config.Database{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("CT_TEST_DATABASE_USERNAME"),
+		Password: os.Getenv("CT_TEST_DATABASE_PASSWORD"),
+		Database: os.Getenv("CT_TEST_DATABASE_DATABASE"),
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("unable to connect: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	err = r.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("unable to migrate: %v", err)
+	}
+	return r
+}
+
+func TestAddURLSameRequestID(t *testing.T) {
+	r := testRepository(t)
+	ctx := context.Background()
+	requestID := "test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	tests := []struct {
+		name    string
+		urlInfo model.UrlInfo
+		want    model.UrlInfo
+	}{
+		{
+			name:    "first crawl",
+			urlInfo: model.UrlInfo{RequestID: requestID, JobID: "job-1", Url: "u", AppName: "first", Success: false},
+			want:    model.UrlInfo{RequestID: requestID, JobID: "job-1", Url: "u", AppName: "first", Success: false},
+		},
+		{
+			name:    "crawled again by another job",
+			urlInfo: model.UrlInfo{RequestID: requestID, JobID: "job-2", Url: "u", AppName: "second", Success: true},
+			want:    model.UrlInfo{RequestID: requestID, JobID: "job-2", Url: "u", AppName: "second", Success: true},
+		},
+		{
+			name:    "crawled again without job",
+			urlInfo: model.UrlInfo{RequestID: requestID, Url: "u", AppName: "third", Success: true},
+			want:    model.UrlInfo{RequestID: requestID, JobID: "job-2", Url: "u", AppName: "third", Success: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.AddURL(ctx, tt.urlInfo)
+			if err != nil {
+				t.Fatalf("AddURL() error = %v", err)
+			}
+			results, err := r.Results(ctx, requestID, 10)
+			if err != nil {
+				t.Fatalf("Results() error = %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("Results() got %d rows, want 1", len(results))
+			}
+			got := results[0]
+			if got.JobID != tt.want.JobID || got.AppName != tt.want.AppName || got.Success != tt.want.Success {
+				t.Errorf("Results() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+// migrations schema changes applied in order when database.migrate is enabled. Keep them idempotent and in sync
+// with docs/dump.sql
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS public.ulrInfo
+	(
+		request_id text NOT NULL,
+		url text NOT NULL,
+		app_name text NOT NULL,
+		rating NUMERIC (10, 2),
+		rating_count INT,
+		success boolean NOT NULL,
+		last_error text,
+		stats json,
+		created_at timestamp with time zone NOT NULL,
+		PRIMARY KEY (request_id)
+	);`,
+	`ALTER TABLE public.ulrInfo
+		ADD COLUMN IF NOT EXISTS developer text,
+		ADD COLUMN IF NOT EXISTS category text,
+		ADD COLUMN IF NOT EXISTS price NUMERIC (10, 2),
+		ADD COLUMN IF NOT EXISTS description text,
+		ADD COLUMN IF NOT EXISTS release_date timestamp with time zone;`,
+	`ALTER TABLE public.ulrInfo
+		ADD COLUMN IF NOT EXISTS unchanged boolean NOT NULL DEFAULT false;`,
+	`ALTER TABLE public.ulrInfo
+		ADD COLUMN IF NOT EXISTS job_id text;`,
+	`CREATE INDEX IF NOT EXISTS ulrinfo_job_id_idx ON public.ulrInfo (job_id);`,
+}
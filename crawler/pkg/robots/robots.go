@@ -0,0 +1,296 @@
+package robots
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/ratelimit"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRobotsSize robots.txt content after this size is ignored
+	maxRobotsSize = 500 * 1024
+	// errorTTL time a host robots.txt fetch error is cached, so a failing host is not requested for every URL
+	errorTTL = 30 * time.Second
+)
+
+// ErrDisallowed the URL is disallowed by the host robots.txt
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Robots robots.txt checker
+type Robots interface {
+	Allowed(ctx context.Context, uri string) error
+}
+
+type robots struct {
+	client  *http.Client
+	limiter ratelimit.Limiter
+	// userAgent sent when fetching the robots.txt, agent the lowercase one used to select the rules
+	userAgent string
+	agent     string
+	ttl       time.Duration
+	mu        sync.Mutex
+	hosts     map[string]*hostRules
+	failures  map[string]failure
+	fetching  map[string]*fetchCall
+}
+
+// failure robots.txt fetch error of a host
+type failure struct {
+	err error
+	at  time.Time
+}
+
+// fetchCall robots.txt fetch of a host in progress, shared by the concurrent requests to the host
+type fetchCall struct {
+	done  chan struct{}
+	rules *hostRules
+	err   error
+}
+
+// hostRules rules of a host for the configured user agent
+type hostRules struct {
+	rules     []rule
+	delay     time.Duration
+	fetchedAt time.Time
+}
+
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// NewRobots returns a robots.txt checker. The rules of each host are cached for the TTL and the Crawl-delay is
+// set as the host delay of the limiter. The client should send the same user agent on the crawl requests
+func NewRobots(client *http.Client, limiter ratelimit.Limiter, userAgent string, ttl time.Duration) Robots {
+	return &robots{
+		client:    client,
+		limiter:   limiter,
+		userAgent: userAgent,
+		agent:     strings.ToLower(userAgent),
+		ttl:       ttl,
+		hosts:     map[string]*hostRules{},
+		failures:  map[string]failure{},
+		fetching:  map[string]*fetchCall{},
+	}
+}
+
+// Allowed returns ErrDisallowed if the URL path is disallowed for the user agent, or an error if the robots.txt
+// could not be fetched
+func (r *robots) Allowed(ctx context.Context, uri string) error {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	rules, err := r.rules(ctx, parsed)
+	if err != nil {
+		return err
+	}
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	if !rules.allowed(path) {
+		return ErrDisallowed
+	}
+	return nil
+}
+
+// rules returns the cached rules of the URL host, fetching them if they are missing or expired. The concurrent
+// requests to a host share a single fetch, and its error is cached for errorTTL
+func (r *robots) rules(ctx context.Context, parsed *url.URL) (*hostRules, error) {
+	host := parsed.Scheme + "://" + parsed.Host
+	r.mu.Lock()
+	if rules, ok := r.hosts[host]; ok && time.Since(rules.fetchedAt) < r.ttl {
+		r.mu.Unlock()
+		return rules, nil
+	}
+	if f, ok := r.failures[host]; ok && time.Since(f.at) < errorTTL {
+		r.mu.Unlock()
+		return nil, f.err
+	}
+	call, ok := r.fetching[host]
+	if !ok {
+		call = &fetchCall{done: make(chan struct{})}
+		r.fetching[host] = call
+		go r.fetchHost(host, parsed.Host, call)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.rules, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchHost fetch the host rules and cache them or the error. It is not bound to a request context, as the
+// fetch is shared by the requests to the host, the client timeout applies
+func (r *robots) fetchHost(host string, hostname string, call *fetchCall) {
+	call.rules, call.err = r.fetch(context.Background(), host)
+	if call.err == nil {
+		r.limiter.SetHostDelay(hostname, call.rules.delay)
+	}
+	r.mu.Lock()
+	delete(r.fetching, host)
+	if call.err == nil {
+		r.hosts[host] = call.rules
+		delete(r.failures, host)
+	} else {
+		r.failures[host] = failure{err: call.err, at: time.Now()}
+	}
+	r.mu.Unlock()
+	close(call.done)
+}
+
+// fetch get and parse the host robots.txt. Missing robots.txt (4xx) allow everything, server errors are returned
+// so the URLs are not crawled until the rules are known
+func (r *robots) fetch(ctx context.Context, host string) (*hostRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parse(io.LimitReader(resp.Body, maxRobotsSize), r.agent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &hostRules{fetchedAt: time.Now()}, nil
+	default:
+		return nil, fmt.Errorf("unable to get robots.txt: %s", resp.Status)
+	}
+}
+
+// group rules of a set of user agents
+type group struct {
+	agents []string
+	rules  []rule
+	delay  time.Duration
+}
+
+// parse the robots.txt content, keeping the rules of the most specific group matching the user agent
+func parse(r io.Reader, userAgent string) *hostRules {
+	var groups []*group
+	var current *group
+	lastWasAgent := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+		switch key {
+		case "user-agent":
+			if !lastWasAgent {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			if current != nil && value != "" {
+				current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if current != nil {
+				seconds, err := strconv.ParseFloat(value, 64)
+				if err == nil && seconds > 0 {
+					current.delay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+
+	var selected *group
+	selectedLen := -1
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			matchLen := -1
+			if agent == "*" {
+				matchLen = 0
+			} else if agent != "" && strings.Contains(userAgent, agent) {
+				matchLen = len(agent)
+			}
+			if matchLen > selectedLen {
+				selected = g
+				selectedLen = matchLen
+			}
+		}
+	}
+	rules := &hostRules{fetchedAt: time.Now()}
+	if selected != nil {
+		rules.rules = selected.rules
+		rules.delay = selected.delay
+	}
+	return rules
+}
+
+// allowed evaluate the path using the longest matching rule, allow rules win ties
+func (h *hostRules) allowed(path string) bool {
+	allowed := true
+	matchLen := -1
+	for _, rule := range h.rules {
+		if !match(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > matchLen || (len(rule.pattern) == matchLen && rule.allow) {
+			allowed = rule.allow
+			matchLen = len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// match a robots.txt path pattern supporting * wildcards and the $ end anchor
+func match(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for _, part := range parts[1:] {
+		i := strings.Index(path[pos:], part)
+		if i < 0 {
+			return false
+		}
+		pos += i + len(part)
+	}
+	if !anchored {
+		return true
+	}
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		return true
+	}
+	// the last literal part must be at the end of the path
+	last := parts[len(parts)-1]
+	return strings.HasSuffix(path, last) && (len(parts) > 1 || len(path) == len(last))
+}
@@ -0,0 +1,187 @@
+package robots
+
+import (
+	"context"
+	"errors"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const robotsTxt = `# comment
+User-agent: *
+Disallow: /private
+Allow: /private/public
+
+User-agent: natscrawler
+User-agent: other-bot
+Disallow: /details/*/reviews$
+Disallow: /search
+Allow: /search/channels
+Crawl-delay: 2
+
+User-agent: natscrawler-images
+Disallow: /
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		wantDelay time.Duration
+		allowed   map[string]bool
+	}{
+		{
+			name:      "default group",
+			userAgent: "somebot",
+			allowed: map[string]bool{
+				"/":                     true,
+				"/private":              false,
+				"/private/page":         false,
+				"/private/public/page":  true,
+				"/search":               true,
+				"/details/123/reviews":  true,
+				"/details/123/channel/": true,
+			},
+		},
+		{
+			name:      "group of the user agent",
+			userAgent: "natscrawler",
+			wantDelay: 2 * time.Second,
+			allowed: map[string]bool{
+				"/private":                true,
+				"/search":                 false,
+				"/search?q=news":          false,
+				"/search/channels?q=news": true,
+				"/details/123/reviews":    false,
+				"/details/123/reviews/2":  true,
+			},
+		},
+		{
+			name:      "group shared by several user agents",
+			userAgent: "other-bot/1.0",
+			wantDelay: 2 * time.Second,
+			allowed: map[string]bool{
+				"/search": false,
+			},
+		},
+		{
+			name:      "most specific group",
+			userAgent: "natscrawler-images",
+			allowed: map[string]bool{
+				"/":                 false,
+				"/search/channels/": false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parse(strings.NewReader(robotsTxt), tt.userAgent)
+			if rules.delay != tt.wantDelay {
+				t.Errorf("parse() delay = %v, want %v", rules.delay, tt.wantDelay)
+			}
+			for path, want := range tt.allowed {
+				if got := rules.allowed(path); got != want {
+					t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "/details", path: "/details/123", want: true},
+		{pattern: "/details", path: "/detail", want: false},
+		{pattern: "/*.json", path: "/api/channel.json", want: true},
+		{pattern: "/*.json$", path: "/api/channel.json?x=1", want: false},
+		{pattern: "/*.json$", path: "/api/channel.json", want: true},
+		{pattern: "/details$", path: "/details", want: true},
+		{pattern: "/details$", path: "/details/1", want: false},
+		{pattern: "/a*b*c", path: "/a-b-c-d", want: true},
+		{pattern: "/a*c*b", path: "/a-b-c", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := match(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("match(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedFetch(t *testing.T) {
+	tests := []struct {
+		name string
+		// status of the robots.txt responses
+		status      int
+		wantErr     bool
+		wantAllowed bool
+	}{
+		{name: "robots.txt", status: http.StatusOK, wantAllowed: false},
+		{name: "missing robots.txt", status: http.StatusNotFound, wantAllowed: true},
+		{name: "server error", status: http.StatusServiceUnavailable, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int64
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt64(&requests, 1)
+				if ua := r.Header.Get("User-Agent"); ua != "NatsCrawler" {
+					t.Errorf("robots.txt requested with User-Agent %q", ua)
+				}
+				<-release
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("User-agent: natscrawler\nDisallow: /search\n"))
+			}))
+			defer server.Close()
+			r := NewRobots(server.Client(), ratelimit.NewLimiter(0), "NatsCrawler", time.Hour)
+
+			// the concurrent requests to the host share the fetch
+			var wg sync.WaitGroup
+			errs := make([]error, 5)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = r.Allowed(context.Background(), server.URL+"/search")
+				}(i)
+			}
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			// the rules, or the error, are cached
+			errs = append(errs, r.Allowed(context.Background(), server.URL+"/search"))
+
+			if got := atomic.LoadInt64(&requests); got != 1 {
+				t.Errorf("robots.txt requested %d times, want 1", got)
+			}
+			for _, err := range errs {
+				switch {
+				case tt.wantErr:
+					if err == nil || errors.Is(err, ErrDisallowed) {
+						t.Errorf("Allowed() error = %v, want a fetch error", err)
+					}
+				case tt.wantAllowed:
+					if err != nil {
+						t.Errorf("Allowed() error = %v, want nil", err)
+					}
+				default:
+					if !errors.Is(err, ErrDisallowed) {
+						t.Errorf("Allowed() error = %v, want %v", err, ErrDisallowed)
+					}
+				}
+			}
+		})
+	}
+}
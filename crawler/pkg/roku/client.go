@@ -0,0 +1,113 @@
+package roku
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/crawler/pkg/cache"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const detailsPath = "/api/v6/channels/detailsunion/"
+
+// Client Roku channel store API client
+type Client interface {
+	Details(ctx context.Context, channel Channel) (*DetailsResponse, error)
+}
+
+// Channel identifies a channel in the store
+type Channel struct {
+	Host     string
+	ID       string
+	Country  string
+	Language string
+}
+
+type client struct {
+	httpClient *http.Client
+	cache      cache.Cache
+}
+
+// NewClient returns a new Roku API client instance. Responses validators are kept in the cache to send
+// conditional requests
+func NewClient(httpClient *http.Client, c cache.Cache) Client {
+	return &client{
+		httpClient: httpClient,
+		cache:      c,
+	}
+}
+
+// DetailsURL returns the detailsunion endpoint URL for the channel
+func DetailsURL(channel Channel) string {
+	uri := url.URL{
+		Scheme: "https",
+		Host:   channel.Host,
+		Path:   detailsPath + channel.ID,
+	}
+	if channel.Country != "" && channel.Language != "" {
+		query := url.Values{}
+		query.Set("country", channel.Country)
+		query.Set("language", channel.Language)
+		uri.RawQuery = query.Encode()
+	}
+	return uri.String()
+}
+
+// Details get the channel details from the detailsunion endpoint.
+// cache.ErrNotModified is returned with the cached details if they did not change since the last successful request
+func (c *client) Details(ctx context.Context, channel Channel) (*DetailsResponse, error) {
+	uri := DetailsURL(channel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, cached, err := cache.Do(c.httpClient, c.cache, uri, req)
+	if errors.Is(err, cache.ErrNotModified) {
+		details, decodeErr := Decode(cached.Data)
+		if decodeErr != nil {
+			return nil, fmt.Errorf("unable to decode cached details: %w", decodeErr)
+		}
+		return details, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get API information: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	details, err := Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	validators := cache.FromResponse(resp)
+	validators.Data = body
+	err = c.cache.Put(uri, validators)
+	if err != nil {
+		logging.FromContext(ctx).Warn("unable to cache response validators", "error", err)
+	}
+	return details, nil
+}
+
+// Decode unmarshal and validate a detailsunion response
+func Decode(body []byte) (*DetailsResponse, error) {
+	var details DetailsResponse
+	err := json.Unmarshal(body, &details)
+	if err != nil {
+		return nil, &ValidationError{Field: "body", Reason: err.Error()}
+	}
+	err = details.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
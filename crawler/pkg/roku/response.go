@@ -0,0 +1,135 @@
+package roku
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxStarRating star ratings are returned in a 0-100 scale
+const maxStarRating = 100
+
+// releaseDateLayouts date layouts used by the API for release dates
+var releaseDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// ValidationError the API response does not have the expected shape
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid API response, %s: %s", e.Field, e.Reason)
+}
+
+// DetailsResponse detailsunion endpoint response
+type DetailsResponse struct {
+	FeedChannel *FeedChannel `json:"feedChannel"`
+}
+
+// FeedChannel channel information
+type FeedChannel struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Developer       string     `json:"developer"`
+	Categories      []Category `json:"categories"`
+	Price           Number     `json:"price"`
+	PriceAsString   string     `json:"priceAsString"`
+	ReleaseDate     string     `json:"releaseDate"`
+	StarRating      Number     `json:"starRating"`
+	StarRatingCount Number     `json:"starRatingCount"`
+}
+
+// Category channel category
+type Category struct {
+	Name string `json:"name"`
+}
+
+// Number a JSON number that could also be sent as a string or null
+type Number struct {
+	Value float64
+	Valid bool
+}
+
+// UnmarshalJSON decode numbers, numeric strings and null values
+func (n *Number) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*n = Number{}
+		return nil
+	}
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+		s = strings.TrimSpace(strings.TrimLeft(s, "$€£"))
+		if s == "" {
+			*n = Number{}
+			return nil
+		}
+	} else {
+		s = string(data)
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = Number{Value: value, Valid: true}
+	return nil
+}
+
+// Validate check the fields required to build a result
+func (d *DetailsResponse) Validate() error {
+	if d.FeedChannel == nil {
+		return &ValidationError{Field: "feedChannel", Reason: "missing"}
+	}
+	if d.FeedChannel.Name == "" {
+		return &ValidationError{Field: "feedChannel.name", Reason: "missing"}
+	}
+	rating := d.FeedChannel.StarRating
+	if !rating.Valid {
+		return &ValidationError{Field: "feedChannel.starRating", Reason: "missing"}
+	}
+	if rating.Value < 0 || rating.Value > maxStarRating {
+		return &ValidationError{Field: "feedChannel.starRating", Reason: fmt.Sprintf("out of range: %v", rating.Value)}
+	}
+	if d.FeedChannel.StarRatingCount.Value < 0 {
+		return &ValidationError{Field: "feedChannel.starRatingCount", Reason: "negative"}
+	}
+	return nil
+}
+
+// Rating returns the star rating in a 0-5 scale
+func (f *FeedChannel) Rating() float64 {
+	rating := (f.StarRating.Value * 5) / maxStarRating
+	return math.Round(rating*100) / 100
+}
+
+// Category returns the channel categories as a comma separated list
+func (f *FeedChannel) Category() string {
+	names := make([]string, 0, len(f.Categories))
+	for _, c := range f.Categories {
+		if c.Name != "" {
+			names = append(names, c.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Released returns the release date, or nil if it is not defined or can not be parsed
+func (f *FeedChannel) Released() *time.Time {
+	for _, layout := range releaseDateLayouts {
+		t, err := time.Parse(layout, f.ReleaseDate)
+		if err == nil {
+			return &t
+		}
+	}
+	return nil
+}
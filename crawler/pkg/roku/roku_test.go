@@ -0,0 +1,99 @@
+package roku
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// wantField field of the validation error, empty if the response is valid
+		wantField  string
+		wantRating float64
+	}{
+		{
+			name:       "valid",
+			body:       `{"feedChannel": {"name": "Channel", "starRating": 90, "starRatingCount": "120"}}`,
+			wantRating: 4.5,
+		},
+		{
+			name:       "rating as string",
+			body:       `{"feedChannel": {"name": "Channel", "starRating": "83.3333", "starRatingCount": null}}`,
+			wantRating: 4.17,
+		},
+		{name: "invalid JSON", body: `{"feedChannel": `, wantField: "body"},
+		{name: "invalid number", body: `{"feedChannel": {"name": "Channel", "starRating": "high"}}`, wantField: "body"},
+		{name: "missing feedChannel", body: `{}`, wantField: "feedChannel"},
+		{name: "null feedChannel", body: `{"feedChannel": null}`, wantField: "feedChannel"},
+		{name: "missing name", body: `{"feedChannel": {"starRating": 90}}`, wantField: "feedChannel.name"},
+		{name: "missing rating", body: `{"feedChannel": {"name": "Channel"}}`, wantField: "feedChannel.starRating"},
+		{name: "null rating", body: `{"feedChannel": {"name": "Channel", "starRating": null}}`, wantField: "feedChannel.starRating"},
+		{name: "rating over range", body: `{"feedChannel": {"name": "Channel", "starRating": 101}}`, wantField: "feedChannel.starRating"},
+		{name: "negative rating", body: `{"feedChannel": {"name": "Channel", "starRating": -1}}`, wantField: "feedChannel.starRating"},
+		{
+			name:      "negative count",
+			body:      `{"feedChannel": {"name": "Channel", "starRating": 90, "starRatingCount": -5}}`,
+			wantField: "feedChannel.starRatingCount",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.body))
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if rating := got.FeedChannel.Rating(); rating != tt.wantRating {
+					t.Errorf("Rating() = %v, want %v", rating, tt.wantRating)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Decode() error = %v, want a validation error", err)
+			}
+			if validationErr.Field != tt.wantField {
+				t.Errorf("Decode() error field = %q, want %q", validationErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestNumberUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Number
+		wantErr bool
+	}{
+		{data: `90`, want: Number{Value: 90, Valid: true}},
+		{data: `4.5`, want: Number{Value: 4.5, Valid: true}},
+		{data: `"120"`, want: Number{Value: 120, Valid: true}},
+		{data: `" 7.5 "`, want: Number{Value: 7.5, Valid: true}},
+		{data: `"$4.99"`, want: Number{Value: 4.99, Valid: true}},
+		{data: `"€1.50"`, want: Number{Value: 1.5, Valid: true}},
+		{data: `null`, want: Number{}},
+		{data: `""`, want: Number{}},
+		{data: `"$"`, want: Number{}},
+		{data: `"free"`, wantErr: true},
+		{data: `"4.5 stars"`, wantErr: true},
+		{data: `true`, wantErr: true},
+		{data: `[1]`, wantErr: true},
+		{data: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			// the number starts valid, so the decoded values are checked to replace it
+			got := Number{Value: -1, Valid: true}
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package selector
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"strings"
+)
+
+// Selector a simple CSS selector supporting tag names, IDs, classes and attribute values
+//
+//	i.e.: h1[itemprop="name"], .Roku-User-Channels, span.rating[itemprop=averageRating], #title
+type Selector struct {
+	tag     string
+	classes []string
+	attrs   map[string]string
+}
+
+// Parse a simple CSS selector. Combinators, selector lists and pseudo-classes are not supported, an error is
+// returned for them instead of matching other elements
+func Parse(s string) (*Selector, error) {
+	sel := &Selector{attrs: map[string]string{}}
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.', '#':
+			end := strings.IndexAny(rest[1:], ".#[ >+~,:")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("selector %q: missing name after %q", s, rest[0])
+			}
+			if rest[0] == '.' {
+				sel.classes = append(sel.classes, name)
+			} else {
+				sel.attrs["id"] = name
+			}
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q: unclosed attribute", s)
+			}
+			name, value := rest[1:end], "*"
+			if i := strings.Index(name, "="); i >= 0 {
+				name, value = name[:i], strings.Trim(strings.TrimSpace(name[i+1:]), `"'`)
+			}
+			name = strings.TrimSpace(name)
+			if name == "" || strings.ContainsAny(name, "~|^$*") {
+				return nil, fmt.Errorf("selector %q: unsupported attribute %q", s, rest[1:end])
+			}
+			sel.attrs[name] = value
+			rest = rest[end+1:]
+		case ' ', '\t', '>', '+', '~':
+			return nil, fmt.Errorf("selector %q: combinators are not supported", s)
+		case ',':
+			return nil, fmt.Errorf("selector %q: selector lists are not supported", s)
+		case ':':
+			return nil, fmt.Errorf("selector %q: pseudo-classes are not supported", s)
+		default:
+			if sel.tag != "" {
+				return nil, fmt.Errorf("selector %q: unexpected %q", s, rest)
+			}
+			end := strings.IndexAny(rest, ".#[ \t>+~,:")
+			if end < 0 {
+				end = len(rest)
+			}
+			sel.tag = strings.ToLower(rest[:end])
+			rest = rest[end:]
+		}
+	}
+	return sel, nil
+}
+
+// Match check if an HTML element node matches the selector
+func (s *Selector) Match(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if s.tag != "" && s.tag != "*" && n.Data != s.tag {
+		return false
+	}
+	for _, class := range s.classes {
+		if !hasClass(n, class) {
+			return false
+		}
+	}
+	for name, value := range s.attrs {
+		attr, ok := Attribute(n, name)
+		if !ok {
+			return false
+		}
+		if value != "*" && attr != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the first node in the tree matching the selector
+func (s *Selector) Find(root *html.Node) *html.Node {
+	if s.Match(root) {
+		return root
+	}
+	for child := root.FirstChild; child != nil; child = child.NextSibling {
+		if n := s.Find(child); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// Attribute returns the value of an attribute of the node
+func Attribute(n *html.Node, name string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func hasClass(n *html.Node, class string) bool {
+	classes, ok := Attribute(n, "class")
+	if !ok {
+		return false
+	}
+	for _, c := range strings.Fields(classes) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package selector
+
+import (
+	"golang.org/x/net/html"
+	"strings"
+	"testing"
+)
+
+const page = `<html><body>
+<div class="Roku-User-Channels app">
+	<h1 id="title" itemprop="name">Channel</h1>
+	<span class="rating big" itemprop="averageRating">4.5</span>
+	<small itemprop="starRating" data-count="120">120 ratings</small>
+</div>
+</body></html>`
+
+func TestParse(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		selector string
+		wantErr  bool
+		// wantText text of the first matching node, empty if none should match
+		wantText string
+	}{
+		{selector: "h1", wantText: "Channel"},
+		{selector: "H1", wantText: "Channel"},
+		{selector: "#title", wantText: "Channel"},
+		{selector: "h1#title", wantText: "Channel"},
+		{selector: "h1#other"},
+		{selector: `h1[itemprop="name"]`, wantText: "Channel"},
+		{selector: "h1[itemprop='name']", wantText: "Channel"},
+		{selector: "[itemprop=averageRating]", wantText: "4.5"},
+		{selector: "span.rating.big[itemprop=averageRating]", wantText: "4.5"},
+		{selector: ".rating", wantText: "4.5"},
+		{selector: ".rating.small"},
+		{selector: "small[data-count]", wantText: "120 ratings"},
+		{selector: "small[data-other]"},
+		{selector: "  span.rating  ", wantText: "4.5"},
+		{selector: "", wantErr: true},
+		{selector: "div h1", wantErr: true},
+		{selector: "div > h1", wantErr: true},
+		{selector: "div>h1", wantErr: true},
+		{selector: "h1 + span", wantErr: true},
+		{selector: "h1 ~ span", wantErr: true},
+		{selector: "h1, span", wantErr: true},
+		{selector: "span:first-child", wantErr: true},
+		{selector: "h1[itemprop", wantErr: true},
+		{selector: "h1[itemprop^=na]", wantErr: true},
+		{selector: "h1.", wantErr: true},
+		{selector: "#", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := Parse(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got string
+			if n := sel.Find(doc); n != nil {
+				got = strings.TrimSpace(n.FirstChild.Data)
+			}
+			if got != tt.wantText {
+				t.Errorf("Find() = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/nats-io/nats.go"
+	"os"
+	"sync"
+	"time"
+)
+
+// Counter names
+const (
+	Processed  = "processed"
+	Succeeded  = "succeeded"
+	Failed     = "failed"
+	Panics     = "panics"
+	Disallowed = "disallowed"
+	// ConfigChanges configuration changes applied by a reload, one per changed setting
+	ConfigChanges = "config_changes"
+	// ConfigRejected configuration reloads rejected because the configuration is invalid
+	ConfigRejected = "config_rejected"
+)
+
+// Telemetry interface
+type Telemetry interface {
+	Inc(name string)
+	Snapshot() map[string]int64
+	Start(ctx context.Context)
+}
+
+type telemetry struct {
+	natsClient *nats.Conn
+	topic      string
+	interval   time.Duration
+	instanceID string
+	mu         sync.Mutex
+	counters   map[string]int64
+}
+
+// Report message published to the monitoring topic
+type Report struct {
+	InstanceID string           `json:"instance_id"`
+	At         time.Time        `json:"at"`
+	Counters   map[string]int64 `json:"counters"`
+}
+
+// NewTelemetry returns a telemetry instance that publishes its counters to the monitoring topic
+func NewTelemetry(nc *nats.Conn, topic string, interval time.Duration) Telemetry {
+	instanceID, _ := os.Hostname()
+	return &telemetry{
+		natsClient: nc,
+		topic:      topic,
+		interval:   interval,
+		instanceID: instanceID,
+		counters:   map[string]int64{},
+	}
+}
+
+// Inc increment a counter
+func (t *telemetry) Inc(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counters[name]++
+}
+
+// Snapshot returns a copy of the counters
+func (t *telemetry) Snapshot() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := make(map[string]int64, len(t.counters))
+	for name, value := range t.counters {
+		snapshot[name] = value
+	}
+	return snapshot
+}
+
+// Start publish the counters periodically until the context is done
+func (t *telemetry) Start(ctx context.Context) {
+	if t.topic == "" || t.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.publish()
+		}
+	}
+}
+
+func (t *telemetry) publish() {
+	data, err := json.Marshal(Report{
+		InstanceID: t.instanceID,
+		At:         time.Now().UTC(),
+		Counters:   t.Snapshot(),
+	})
+	if err != nil {
+		logging.L().Error("unable to encode telemetry report", "error", err)
+		return
+	}
+	err = t.natsClient.Publish(t.topic, data)
+	if err != nil {
+		logging.L().Error("unable to publish telemetry report", "error", err)
+	}
+}
@@ -9,5 +9,14 @@ CREATE TABLE IF NOT EXISTS public.ulrInfo
     last_error text,
     stats json,
     created_at timestamp with time zone NOT NULL,
+    developer text,
+    category text,
+    price NUMERIC (10, 2),
+    description text,
+    release_date timestamp with time zone,
+    unchanged boolean NOT NULL DEFAULT false,
+    job_id text,
     PRIMARY KEY (request_id)
-);
\ No newline at end of file
+);
+
+CREATE INDEX IF NOT EXISTS ulrinfo_job_id_idx ON public.ulrInfo (job_id);
\ No newline at end of file
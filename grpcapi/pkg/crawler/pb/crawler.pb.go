@@ -27,6 +27,7 @@ const (
 	UrlResponse_STATUS_ACCEPTED    UrlResponse_Status = 1 // URL was accepted and is ready to be processed
 	UrlResponse_STATUS_RETRY       UrlResponse_Status = 2 // There is an issue and the service is requiring to retry later
 	UrlResponse_STATUS_UNAVAILABLE UrlResponse_Status = 3 // The service is unavailable
+	UrlResponse_STATUS_DUPLICATE   UrlResponse_Status = 4 // The URL was recently queued, the response has the request ID of the queued URL
 )
 
 // Enum value maps for UrlResponse_Status.
@@ -36,12 +37,14 @@ var (
 		1: "STATUS_ACCEPTED",
 		2: "STATUS_RETRY",
 		3: "STATUS_UNAVAILABLE",
+		4: "STATUS_DUPLICATE",
 	}
 	UrlResponse_Status_value = map[string]int32{
 		"STATUS_UNKNOWN":     0,
 		"STATUS_ACCEPTED":    1,
 		"STATUS_RETRY":       2,
 		"STATUS_UNAVAILABLE": 3,
+		"STATUS_DUPLICATE":   4,
 	}
 )
 
@@ -72,14 +75,65 @@ func (UrlResponse_Status) EnumDescriptor() ([]byte, []int) {
 	return file_crawler_proto_rawDescGZIP(), []int{1, 0}
 }
 
+type CancelResponse_Status int32
+
+const (
+	CancelResponse_STATUS_UNKNOWN     CancelResponse_Status = 0
+	CancelResponse_STATUS_ACCEPTED    CancelResponse_Status = 1 // The cancellation was sent to the crawler services
+	CancelResponse_STATUS_UNAVAILABLE CancelResponse_Status = 2 // The service is unavailable
+)
+
+// Enum value maps for CancelResponse_Status.
+var (
+	CancelResponse_Status_name = map[int32]string{
+		0: "STATUS_UNKNOWN",
+		1: "STATUS_ACCEPTED",
+		2: "STATUS_UNAVAILABLE",
+	}
+	CancelResponse_Status_value = map[string]int32{
+		"STATUS_UNKNOWN":     0,
+		"STATUS_ACCEPTED":    1,
+		"STATUS_UNAVAILABLE": 2,
+	}
+)
+
+func (x CancelResponse_Status) Enum() *CancelResponse_Status {
+	p := new(CancelResponse_Status)
+	*p = x
+	return p
+}
+
+func (x CancelResponse_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CancelResponse_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_crawler_proto_enumTypes[1].Descriptor()
+}
+
+func (CancelResponse_Status) Type() protoreflect.EnumType {
+	return &file_crawler_proto_enumTypes[1]
+}
+
+func (x CancelResponse_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CancelResponse_Status.Descriptor instead.
+func (CancelResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return file_crawler_proto_rawDescGZIP(), []int{3, 0}
+}
+
 // URL request
 type UrlRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	RequestId string `protobuf:"bytes,1,opt,name=requestId,proto3" json:"requestId,omitempty"`
-	Url       string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	RequestId  string `protobuf:"bytes,1,opt,name=requestId,proto3" json:"requestId,omitempty"`
+	Url        string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	DeadlineMs int64  `protobuf:"varint,3,opt,name=deadlineMs,proto3" json:"deadlineMs,omitempty"` // Time in milliseconds allowed to process the URL, zero uses the crawler default
+	Force      bool   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`           // Queue the URL even if it is a duplicate of a recently queued URL
 }
 
 func (x *UrlRequest) Reset() {
@@ -128,6 +182,20 @@ func (x *UrlRequest) GetUrl() string {
 	return ""
 }
 
+func (x *UrlRequest) GetDeadlineMs() int64 {
+	if x != nil {
+		return x.DeadlineMs
+	}
+	return 0
+}
+
+func (x *UrlRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 // URL response
 type UrlResponse struct {
 	state         protoimpl.MessageState
@@ -184,33 +252,160 @@ func (x *UrlResponse) GetStatus() UrlResponse_Status {
 	return UrlResponse_STATUS_UNKNOWN
 }
 
+// Cancel request
+type CancelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string `protobuf:"bytes,1,opt,name=requestId,proto3" json:"requestId,omitempty"`
+}
+
+func (x *CancelRequest) Reset() {
+	*x = CancelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_crawler_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRequest) ProtoMessage() {}
+
+func (x *CancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_crawler_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequest) Descriptor() ([]byte, []int) {
+	return file_crawler_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CancelRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// Cancel response
+type CancelResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string                `protobuf:"bytes,1,opt,name=requestId,proto3" json:"requestId,omitempty"`
+	Status    CancelResponse_Status `protobuf:"varint,2,opt,name=status,proto3,enum=crawler.CancelResponse_Status" json:"status,omitempty"`
+}
+
+func (x *CancelResponse) Reset() {
+	*x = CancelResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_crawler_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CancelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelResponse) ProtoMessage() {}
+
+func (x *CancelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_crawler_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelResponse.ProtoReflect.Descriptor instead.
+func (*CancelResponse) Descriptor() ([]byte, []int) {
+	return file_crawler_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CancelResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CancelResponse) GetStatus() CancelResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return CancelResponse_STATUS_UNKNOWN
+}
+
 var File_crawler_proto protoreflect.FileDescriptor
 
 var file_crawler_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x07, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x22, 0x3c, 0x0a, 0x0a, 0x55, 0x72, 0x6c, 0x52,
+	0x07, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x22, 0x72, 0x0a, 0x0a, 0x55, 0x72, 0x6c, 0x52,
 	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0xbd, 0x01, 0x0a, 0x0b, 0x55, 0x72, 0x6c, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x55,
-	0x72, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x5b, 0x0a, 0x06, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e,
-	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54, 0x55,
-	0x53, 0x5f, 0x41, 0x43, 0x43, 0x45, 0x50, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c,
-	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x54, 0x52, 0x59, 0x10, 0x02, 0x12, 0x16,
-	0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x41, 0x56, 0x41, 0x49, 0x4c,
-	0x41, 0x42, 0x4c, 0x45, 0x10, 0x03, 0x32, 0x4b, 0x0a, 0x0e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69,
+	0x6e, 0x65, 0x4d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x64, 0x65, 0x61, 0x64,
+	0x6c, 0x69, 0x6e, 0x65, 0x4d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x22, 0xd3, 0x01, 0x0a,
+	0x0b, 0x55, 0x72, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x63, 0x72, 0x61,
+	0x77, 0x6c, 0x65, 0x72, 0x2e, 0x55, 0x72, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22,
+	0x71, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x13, 0x0a,
+	0x0f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x41, 0x43, 0x43, 0x45, 0x50, 0x54, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x54,
+	0x52, 0x59, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55,
+	0x4e, 0x41, 0x56, 0x41, 0x49, 0x4c, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x55, 0x50, 0x4c, 0x49, 0x43, 0x41, 0x54, 0x45,
+	0x10, 0x04, 0x22, 0x2d, 0x0a, 0x0d, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49,
+	0x64, 0x22, 0xb1, 0x01, 0x0a, 0x0e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x49, 0x64, 0x12, 0x36, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x61, 0x6e,
+	0x63, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x49, 0x0a, 0x06, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54,
+	0x55, 0x53, 0x5f, 0x41, 0x43, 0x43, 0x45, 0x50, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x16, 0x0a,
+	0x12, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x41, 0x56, 0x41, 0x49, 0x4c, 0x41,
+	0x42, 0x4c, 0x45, 0x10, 0x02, 0x32, 0x8f, 0x01, 0x0a, 0x0e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65,
 	0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x50, 0x72, 0x6f, 0x63,
 	0x65, 0x73, 0x73, 0x55, 0x72, 0x6c, 0x12, 0x13, 0x2e, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72,
 	0x2e, 0x55, 0x72, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x63, 0x72,
 	0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x55, 0x72, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x00, 0x42, 0x05, 0x5a, 0x03, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x65, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0d, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43,
+	0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x63,
+	0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x05, 0x5a, 0x03, 0x2f, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -225,22 +420,28 @@ func file_crawler_proto_rawDescGZIP() []byte {
 	return file_crawler_proto_rawDescData
 }
 
-var file_crawler_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_crawler_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_crawler_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_crawler_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_crawler_proto_goTypes = []interface{}{
-	(UrlResponse_Status)(0), // 0: crawler.UrlResponse.Status
-	(*UrlRequest)(nil),      // 1: crawler.UrlRequest
-	(*UrlResponse)(nil),     // 2: crawler.UrlResponse
+	(UrlResponse_Status)(0),    // 0: crawler.UrlResponse.Status
+	(CancelResponse_Status)(0), // 1: crawler.CancelResponse.Status
+	(*UrlRequest)(nil),         // 2: crawler.UrlRequest
+	(*UrlResponse)(nil),        // 3: crawler.UrlResponse
+	(*CancelRequest)(nil),      // 4: crawler.CancelRequest
+	(*CancelResponse)(nil),     // 5: crawler.CancelResponse
 }
 var file_crawler_proto_depIdxs = []int32{
 	0, // 0: crawler.UrlResponse.status:type_name -> crawler.UrlResponse.Status
-	1, // 1: crawler.CrawlerService.ProcessUrl:input_type -> crawler.UrlRequest
-	2, // 2: crawler.CrawlerService.ProcessUrl:output_type -> crawler.UrlResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1, // 1: crawler.CancelResponse.status:type_name -> crawler.CancelResponse.Status
+	2, // 2: crawler.CrawlerService.ProcessUrl:input_type -> crawler.UrlRequest
+	4, // 3: crawler.CrawlerService.CancelRequest:input_type -> crawler.CancelRequest
+	3, // 4: crawler.CrawlerService.ProcessUrl:output_type -> crawler.UrlResponse
+	5, // 5: crawler.CrawlerService.CancelRequest:output_type -> crawler.CancelResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_crawler_proto_init() }
@@ -273,14 +474,38 @@ func file_crawler_proto_init() {
 				return nil
 			}
 		}
+		file_crawler_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_crawler_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_crawler_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   2,
+			NumEnums:      2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
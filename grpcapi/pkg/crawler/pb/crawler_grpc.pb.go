@@ -24,6 +24,8 @@ const _ = grpc.SupportPackageIsVersion7
 type CrawlerServiceClient interface {
 	// Process the URL and returns an status
 	ProcessUrl(ctx context.Context, in *UrlRequest, opts ...grpc.CallOption) (*UrlResponse, error)
+	// Cancel a queued or in-flight URL request
+	CancelRequest(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
 }
 
 type crawlerServiceClient struct {
@@ -43,12 +45,23 @@ func (c *crawlerServiceClient) ProcessUrl(ctx context.Context, in *UrlRequest, o
 	return out, nil
 }
 
+func (c *crawlerServiceClient) CancelRequest(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
+	out := new(CancelResponse)
+	err := c.cc.Invoke(ctx, "/crawler.CrawlerService/CancelRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CrawlerServiceServer is the server API for CrawlerService service.
 // All implementations must embed UnimplementedCrawlerServiceServer
 // for forward compatibility
 type CrawlerServiceServer interface {
 	// Process the URL and returns an status
 	ProcessUrl(context.Context, *UrlRequest) (*UrlResponse, error)
+	// Cancel a queued or in-flight URL request
+	CancelRequest(context.Context, *CancelRequest) (*CancelResponse, error)
 	mustEmbedUnimplementedCrawlerServiceServer()
 }
 
@@ -59,6 +72,9 @@ type UnimplementedCrawlerServiceServer struct {
 func (UnimplementedCrawlerServiceServer) ProcessUrl(context.Context, *UrlRequest) (*UrlResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessUrl not implemented")
 }
+func (UnimplementedCrawlerServiceServer) CancelRequest(context.Context, *CancelRequest) (*CancelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelRequest not implemented")
+}
 func (UnimplementedCrawlerServiceServer) mustEmbedUnimplementedCrawlerServiceServer() {}
 
 // UnsafeCrawlerServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -90,6 +106,24 @@ func _CrawlerService_ProcessUrl_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CrawlerService_CancelRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrawlerServiceServer).CancelRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/crawler.CrawlerService/CancelRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrawlerServiceServer).CancelRequest(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CrawlerService_ServiceDesc is the grpc.ServiceDesc for CrawlerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -101,6 +135,10 @@ var CrawlerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessUrl",
 			Handler:    _CrawlerService_ProcessUrl_Handler,
 		},
+		{
+			MethodName: "CancelRequest",
+			Handler:    _CrawlerService_CancelRequest_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "crawler.proto",
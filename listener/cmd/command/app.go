@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/listener/config"
 	"github.com/StevenRojas/natscrawler/listener/pkg/listener"
 	"github.com/spf13/cobra"
@@ -9,16 +10,14 @@ import (
 
 // NewRootCommand creates the root command
 func NewRootCommand(ctx context.Context) *cobra.Command {
-	rootCommand := &cobra.Command{
-		Use:   "listen",
-		Short: "Listen for URLs to be crawled",
-
-		PersistentPreRunE: config.Setup,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return listen()
-		},
+	rootCommand := NewCommand(ctx)
+	rootCommand.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		err := config.Setup(cmd, args)
+		if err != nil {
+			return err
+		}
+		return logging.Setup(logging.Output(config.App.Log), config.App.Log, "service", "listener")
 	}
-
 	rootCommand.Flags().StringVarP(
 		&config.Filename,
 		"config",
@@ -30,6 +29,25 @@ func NewRootCommand(ctx context.Context) *cobra.Command {
 	return rootCommand
 }
 
-func listen() error {
-	return listener.NewListener(config.App)
+// NewCommand creates the command that listens for URLs. The configuration is expected to be loaded by the caller,
+// so it can be used by other CLIs
+func NewCommand(ctx context.Context) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "listen",
+		Short: "Listen for URLs to be crawled",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listen(ctx)
+		},
+	}
+	config.AddFlags(command)
+
+	return command
+}
+
+func listen(ctx context.Context) error {
+	l, err := listener.NewListener(config.App)
+	if err != nil {
+		return err
+	}
+	return l.Run(ctx)
 }
\ No newline at end of file
@@ -2,34 +2,31 @@ package main
 
 import (
 	"context"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/listener/cmd/command"
-	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 )
 
-const closeFallbackTime = 3
-
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	done := make(chan bool, 1)
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
 	defer func() {
-		log.Println("closing application")
+		logging.L().Info("closing application")
 		signal.Stop(signals)
 		cancel()
 	}()
 
 	go func() {
 		<-signals
-		log.Println("propagating cancel signal")
+		logging.L().Info("propagating cancel signal, stopping the listener (send it again to force the exit)")
 		cancel()
 		done <- true
-		time.Sleep(closeFallbackTime * time.Second)
-		log.Println("fallback exit")
+		<-signals
+		logging.L().Warn("forced exit")
 		os.Exit(1)
 	}()
 
@@ -1,10 +1,11 @@
 package config
 
 import (
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/common/pkg/natsconn"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
-	"strings"
 	"time"
 )
 
@@ -16,96 +17,167 @@ var (
 	Filename string
 	// App configuration struct
 	App AppConfig
-
-	// environmentVarList list of environment variables read by the app. The name should match with a struct field.
-	// The dots will be replaced by underscores, it will be capitalized and the environmentPrefix will be added
-	// 		i.e.: nats.host => CT_L_NATS_HOST
-	environmentVarList = []string{
-		"nats.host",
-	}
 )
 
 // AppConfig struct
 type AppConfig struct {
-	Grpc       GrpcServer `mapstructure:"grpc"`
-	Nats       NatsServer `mapstructure:"nats"`
-	Queue      Queue      `mapstructure:"queue"`
-	Monitoring Monitoring `mapstructure:"monitor"`
+	Grpc       GrpcServer          `mapstructure:"grpc"`
+	Nats       natsconn.Config     `mapstructure:"nats"`
+	Queue      natsconn.Queue      `mapstructure:"queue"`
+	Monitoring natsconn.Monitoring `mapstructure:"monitor"`
+	Auth       Auth                `mapstructure:"auth"`
+	Dedup      Dedup               `mapstructure:"dedup"`
+	Log        logging.Config      `mapstructure:"log"`
+}
+
+// Dedup deduplication window of the queued URLs, the duplicates are answered with the request ID that queued the URL
+type Dedup struct {
+	// Window time a URL is remembered after it is queued, the deduplication is disabled if it is 0
+	Window time.Duration `mapstructure:"window"`
+	// MaxEntries URLs remembered, the oldest ones are forgotten first
+	MaxEntries int `mapstructure:"max_entries"`
 }
 
 // GrpcServer GRPC server configuration
 type GrpcServer struct {
 	Address string `mapstructure:"address"`
+	// ShutdownTimeout time allowed to the pending requests to finish on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	TLS             ServerTLS     `mapstructure:"tls"`
+}
+
+// ServerTLS GRPC server TLS configuration, the files are reloaded when they change
+type ServerTLS struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile CA bundle used to verify the client certificates, they are required if it is set (mTLS)
+	ClientCAFile   string        `mapstructure:"client_ca_file"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// Auth GRPC clients authentication and limits
+type Auth struct {
+	Enabled bool `mapstructure:"enabled"`
+	// APIKeys static bearer tokens, the client name is the identity attached to the requests
+	APIKeys []APIKey `mapstructure:"api_keys"`
+	JWT     JWT      `mapstructure:"jwt"`
+	Limits  Limits   `mapstructure:"limits"`
+}
+
+// APIKey static API key of a client
+type APIKey struct {
+	Client string `mapstructure:"client"`
+	Key    string `mapstructure:"key" secret:"true"`
+}
+
+// JWT signed tokens configuration, the token subject is the client identity
+type JWT struct {
+	// KeyFile HS256 secret or RS256 PEM public key used to verify the tokens, JWTs are not accepted if it is empty
+	KeyFile  string `mapstructure:"key_file"`
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+}
+
+// Limits per client limits, the default limit applies to the clients without their own
+type Limits struct {
+	Default Limit         `mapstructure:"default"`
+	Clients []ClientLimit `mapstructure:"clients"`
 }
 
-// NatsServer NATS server configuration
-type NatsServer struct {
-	Host                  string `mapstructure:"host"`
-	AllowReconnect        bool   `mapstructure:"allow_reconnect"`
-	MaxReconnectAttempts  int    `mapstructure:"max_reconnect_attempts"`
-	ReconnectWaitDuration string `mapstructure:"reconnect_wait"`
-	TimeoutDuration       string `mapstructure:"timeout"`
-	ReconnectWait         time.Duration
-	Timeout               time.Duration
+// ClientLimit limit of a client
+type ClientLimit struct {
+	Client string `mapstructure:"client"`
+	Limit  `mapstructure:",squash"`
 }
 
-// Queue NATS queue configuration
-type Queue struct {
-	Topic string `mapstructure:"topic"`
-	Group string `mapstructure:"group"`
+// Limit requests allowed to a client
+type Limit struct {
+	// Rate requests per second, unlimited if 0
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+	// DailyQuota requests per UTC day, unlimited if 0
+	DailyQuota int64 `mapstructure:"daily_quota"`
 }
 
-// Monitoring configuration
-type Monitoring struct {
-	Topic string `mapstructure:"topic"`
+// AddFlags add a flag overriding each configuration key
+func AddFlags(cmd *cobra.Command) {
+	appconfig.AddFlags(cmd.Flags(), environmentPrefix, &App)
 }
 
-// Setup bind command flags and environment variables
-// The precedence to override a configuration is: flag -> environment variable -> configuration field
+// Setup load the configuration file, overridden by the command flags and environment variables
 func Setup(cmd *cobra.Command, _ []string) error {
-	v := viper.New()
-	v.SetConfigFile(Filename)
-	v.SetConfigType("yaml")
-	v.AddConfigPath("./config")
-
-	v.SetEnvPrefix(environmentPrefix)
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	err := v.ReadInConfig()
-	if err != nil {
-		return err
-	}
+	return appconfig.Load(cmd, Filename, environmentPrefix, &App)
+}
 
-	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
-		_ = v.BindPFlag(flag.Name, cmd.Flags().Lookup(flag.Name))
-	})
-	for _, env := range environmentVarList {
-		_ = v.BindPFlag(env, cmd.Flags().Lookup(env))
+// Validate the configuration
+func (c *AppConfig) Validate() error {
+	var errs appconfig.Errors
+	errs.Merge("log", c.Log.Validate())
+	errs.Merge("grpc", c.Grpc.Validate())
+	errs.Merge("nats", c.Nats.Validate())
+	errs.Merge("queue", c.Queue.Validate())
+	errs.Merge("auth", c.Auth.Validate())
+	errs.Merge("dedup", c.Dedup.Validate())
+	return errs.Err()
+}
+
+// Validate the GRPC server configuration
+func (g GrpcServer) Validate() error {
+	var errs appconfig.Errors
+	errs.HostPort("address", g.Address)
+	errs.Positive("shutdown_timeout", g.ShutdownTimeout)
+	if g.TLS.Enabled {
+		errs.Required("tls.cert_file", g.TLS.CertFile)
+		errs.Required("tls.key_file", g.TLS.KeyFile)
+		errs.NotNegative("tls.reload_interval", g.TLS.ReloadInterval)
 	}
+	return errs.Err()
+}
 
-	err = v.Unmarshal(&App)
-	if err != nil {
-		return err
+// Validate the authentication configuration
+func (a Auth) Validate() error {
+	var errs appconfig.Errors
+	if !a.Enabled {
+		return nil
+	}
+	if len(a.APIKeys) == 0 && a.JWT.KeyFile == "" {
+		errs.Add("enabled", "api_keys or jwt.key_file is required when it is enabled")
 	}
-	err = parseDurations()
-	if err != nil {
-		return err
+	for i, apiKey := range a.APIKeys {
+		errs.Required(fmt.Sprintf("api_keys[%d].client", i), apiKey.Client)
+		errs.Required(fmt.Sprintf("api_keys[%d].key", i), apiKey.Key)
 	}
-	return nil
+	errs.Merge("limits.default", a.Limits.Default.Validate())
+	for i, client := range a.Limits.Clients {
+		key := fmt.Sprintf("limits.clients[%d]", i)
+		errs.Required(key+".client", client.Client)
+		errs.Merge(key, client.Limit.Validate())
+	}
+	return errs.Err()
 }
 
-// parseDurations parse string durations (1s, 2h, etc.) into time.Duration values
-func parseDurations() error {
-	var err error
-	App.Nats.ReconnectWait, err = time.ParseDuration(App.Nats.ReconnectWaitDuration)
-	if err != nil {
-		return err
-	}
-	App.Nats.Timeout, err = time.ParseDuration(App.Nats.TimeoutDuration)
-	if err != nil {
-		return err
+// Validate the deduplication window, only if it is enabled
+func (d Dedup) Validate() error {
+	var errs appconfig.Errors
+	errs.NotNegative("window", d.Window)
+	if d.Window > 0 && d.MaxEntries < 1 {
+		errs.Add("max_entries", "must be at least 1 when the window is set, got %d", d.MaxEntries)
 	}
+	return errs.Err()
+}
 
-	return nil
+// Validate the client limit
+func (l Limit) Validate() error {
+	var errs appconfig.Errors
+	if l.Rate < 0 {
+		errs.Add("rate", "can not be negative")
+	}
+	if l.Rate > 0 && l.Burst < 1 {
+		errs.Add("burst", "must be at least 1 when the rate is set, got %d", l.Burst)
+	}
+	if l.DailyQuota < 0 {
+		errs.Add("daily_quota", "can not be negative")
+	}
+	return errs.Err()
 }
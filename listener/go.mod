@@ -3,12 +3,14 @@ module github.com/StevenRojas/natscrawler/listener
 go 1.18
 
 require (
+	github.com/StevenRojas/natscrawler/common v0.0.0
 	github.com/StevenRojas/natscrawler/grpcapi v0.0.0
 	github.com/golang/protobuf v1.5.2
 	github.com/nats-io/nats.go v1.13.1-0.20220308171302-2f2f6968e98d
 	github.com/spf13/cobra v1.4.0
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.10.1
+	golang.org/x/time v0.0.0-20211116232009-f0f3c7e86c11
 	google.golang.org/grpc v1.43.0
 )
 
@@ -37,3 +39,5 @@ require (
 )
 
 replace github.com/StevenRojas/natscrawler/grpcapi v0.0.0 => ../grpcapi
+
+replace github.com/StevenRojas/natscrawler/common v0.0.0 => ../common
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/listener/config"
+	"strings"
+)
+
+var (
+	// ErrMissingToken the request has no bearer token
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrInvalidToken the token is not a known API key or a valid JWT
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Authenticator validates bearer tokens and returns the client identity
+type Authenticator interface {
+	Authenticate(token string) (string, error)
+}
+
+type authenticator struct {
+	// apiKeys client name by API key hash, so the lookup does not depend on the key content
+	apiKeys map[[sha256.Size]byte]string
+	jwt     *jwtVerifier
+}
+
+// NewAuthenticator returns an authenticator accepting the static API keys and, if a key file is set, the JWTs
+// signed with it
+func NewAuthenticator(conf config.Auth) (Authenticator, error) {
+	a := &authenticator{
+		apiKeys: map[[sha256.Size]byte]string{},
+	}
+	for _, apiKey := range conf.APIKeys {
+		if apiKey.Key == "" || apiKey.Client == "" {
+			return nil, fmt.Errorf("API keys require a key and a client name")
+		}
+		a.apiKeys[sha256.Sum256([]byte(apiKey.Key))] = apiKey.Client
+	}
+	if conf.JWT.KeyFile != "" {
+		verifier, err := newJWTVerifier(conf.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+	return a, nil
+}
+
+// Authenticate returns the client name of the API key, or the subject of the JWT
+func (a *authenticator) Authenticate(token string) (string, error) {
+	if token == "" {
+		return "", ErrMissingToken
+	}
+	if client, ok := a.apiKeys[sha256.Sum256([]byte(token))]; ok {
+		return client, nil
+	}
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		return a.jwt.verify(token)
+	}
+	return "", ErrInvalidToken
+}
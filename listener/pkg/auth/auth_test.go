@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/StevenRojas/natscrawler/listener/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const secret = "shared-secret"
+
+func TestAuthenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretFile := writeKey(t, "secret", []byte(secret+"\n"))
+	publicKeyFile := writeKey(t, "public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+
+	now := time.Now()
+	valid := map[string]interface{}{"sub": "client", "iss": "issuer", "aud": "listener", "exp": now.Add(time.Hour).Unix()}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := map[string]interface{}{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	tests := []struct {
+		name       string
+		keyFile    string
+		token      string
+		wantClient string
+		wantErr    error
+	}{
+		{name: "API key", token: "api-key", wantClient: "api-client"},
+		{name: "missing token", wantErr: ErrMissingToken},
+		{name: "unknown API key", token: "other-key", wantErr: ErrInvalidToken},
+		{name: "HS256", keyFile: secretFile, token: signHS256(t, secret, valid), wantClient: "client"},
+		{name: "HS256 wrong secret", keyFile: secretFile, token: signHS256(t, "other-secret", valid), wantErr: ErrInvalidToken},
+		{name: "RS256", keyFile: publicKeyFile, token: signRS256(t, rsaKey, valid), wantClient: "client"},
+		{name: "RS256 wrong key", keyFile: publicKeyFile, token: signRS256(t, otherKey, valid), wantErr: ErrInvalidToken},
+		{name: "HS256 with RS256 key", keyFile: publicKeyFile, token: signHS256(t, secret, valid), wantErr: ErrInvalidToken},
+		{name: "JWT disabled", token: signHS256(t, secret, valid), wantErr: ErrInvalidToken},
+		{name: "expired", keyFile: secretFile, token: signHS256(t, secret, with("exp", now.Add(-time.Minute).Unix())),
+			wantErr: ErrInvalidToken},
+		{name: "not valid yet", keyFile: secretFile, token: signHS256(t, secret, with("nbf", now.Add(time.Hour).Unix())),
+			wantErr: ErrInvalidToken},
+		{name: "missing subject", keyFile: secretFile, token: signHS256(t, secret, with("sub", "")), wantErr: ErrInvalidToken},
+		{name: "unexpected issuer", keyFile: secretFile, token: signHS256(t, secret, with("iss", "other")),
+			wantErr: ErrInvalidToken},
+		{name: "audience list", keyFile: secretFile, token: signHS256(t, secret, with("aud", []string{"other", "listener"})),
+			wantClient: "client"},
+		{name: "unexpected audience", keyFile: secretFile, token: signHS256(t, secret, with("aud", []string{"other"})),
+			wantErr: ErrInvalidToken},
+		{name: "malformed", keyFile: secretFile, token: "a.b.c", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAuthenticator(config.Auth{
+				APIKeys: []config.APIKey{{Key: "api-key", Client: "api-client"}},
+				JWT:     config.JWT{KeyFile: tt.keyFile, Issuer: "issuer", Audience: "listener"},
+			})
+			if err != nil {
+				t.Fatalf("NewAuthenticator() error = %v", err)
+			}
+			client, err := a.Authenticate(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if client != tt.wantClient {
+				t.Errorf("Authenticate() = %q, want %q", client, tt.wantClient)
+			}
+		})
+	}
+}
+
+func writeKey(t *testing.T, name string, content []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// unsigned returns the encoded header and claims of a token
+func unsigned(t *testing.T, alg string, claims map[string]interface{}) string {
+	header, err := json.Marshal(jwtHeader{Alg: alg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	signed := unsigned(t, "HS256", claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	signed := unsigned(t, "RS256", claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/listener/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// healthService the health checks are not authenticated, so the probes do not need a token
+const healthService = "/grpc.health.v1.Health/"
+
+type clientKey struct{}
+
+// Interceptor authenticates the GRPC requests and enforces the client limits
+type Interceptor interface {
+	Unary() grpc.UnaryServerInterceptor
+	Stream() grpc.StreamServerInterceptor
+}
+
+type interceptor struct {
+	authenticator Authenticator
+	limiter       Limiter
+}
+
+// NewInterceptor returns an interceptor using the configured API keys, JWT key and limits
+func NewInterceptor(conf config.Auth) (Interceptor, error) {
+	authenticator, err := NewAuthenticator(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &interceptor{
+		authenticator: authenticator,
+		limiter:       NewLimiter(conf.Limits),
+	}, nil
+}
+
+// ClientFromContext returns the authenticated client of the request, empty if authentication is disabled
+func ClientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// Unary returns the unary server interceptor
+func (i *interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize authenticate the request bearer token and count it against the client limits. The returned context
+// has the client identity
+func (i *interceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthService) {
+		return ctx, nil
+	}
+	client, err := i.authenticator.Authenticate(bearerToken(ctx))
+	if errors.Is(err, ErrMissingToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		logging.L().Warn("rejected request", "method", method, "error", err)
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	err = i.limiter.Allow(client)
+	if err != nil {
+		return nil, status.Errorf(codes.ResourceExhausted, "%s: %s", client, err.Error())
+	}
+	return context.WithValue(ctx, clientKey{}, client), nil
+}
+
+// bearerToken returns the token of the authorization metadata
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+			return strings.TrimSpace(value[7:])
+		}
+	}
+	return ""
+}
+
+// serverStream overrides the stream context with the authorized one
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/listener/config"
+	"os"
+	"strings"
+	"time"
+)
+
+// jwtVerifier verifies HS256 tokens signed with a shared secret, or RS256 tokens signed with the private key of
+// the configured public key
+type jwtVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt int64           `json:"exp"`
+	NotBefore int64           `json:"nbf"`
+}
+
+// newJWTVerifier load the key file, a PEM public key selects RS256 and any other content is used as HS256 secret
+func newJWTVerifier(conf config.JWT) (*jwtVerifier, error) {
+	content, err := os.ReadFile(conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read JWT key: %w", err)
+	}
+	v := &jwtVerifier{
+		issuer:   conf.Issuer,
+		audience: conf.Audience,
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		v.secret = bytes.TrimSpace(content)
+		if len(v.secret) == 0 {
+			return nil, fmt.Errorf("empty JWT secret at %s", conf.KeyFile)
+		}
+		return v, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse JWT public key: %w", err)
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("JWT public key must be RSA")
+	}
+	v.publicKey = publicKey
+	return v, nil
+}
+
+// verify check the signature and the registered claims, returning the token subject
+func (v *jwtVerifier) verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	var header jwtHeader
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return "", err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == "HS256" && v.secret != nil:
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return "", ErrInvalidToken
+		}
+	case header.Alg == "RS256" && v.publicKey != nil:
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return "", ErrInvalidToken
+		}
+	default:
+		return "", fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	var claims jwtClaims
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	switch {
+	case claims.Subject == "":
+		return "", fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	case claims.ExpiresAt != 0 && now >= claims.ExpiresAt:
+		return "", fmt.Errorf("%w: expired", ErrInvalidToken)
+	case claims.NotBefore != 0 && now < claims.NotBefore:
+		return "", fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return "", fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	case v.audience != "" && !claims.hasAudience(v.audience):
+		return "", fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return claims.Subject, nil
+}
+
+// hasAudience the audience claim can be a string or a list of strings
+func (c jwtClaims) hasAudience(audience string) bool {
+	var single string
+	if json.Unmarshal(c.Audience, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(c.Audience, &list) == nil {
+		for _, value := range list {
+			if value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if json.Unmarshal(data, v) != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"github.com/StevenRojas/natscrawler/listener/config"
+	"golang.org/x/time/rate"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited the client sent too many requests per second
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrQuotaExceeded the client used its daily quota
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+// Limiter per client rate limits and daily quotas
+type Limiter interface {
+	Allow(client string) error
+}
+
+type limiter struct {
+	defaults config.Limit
+	limits   map[string]config.Limit
+	mu       sync.Mutex
+	clients  map[string]*clientUsage
+}
+
+// clientUsage rate limiter and requests of the current UTC day of a client
+type clientUsage struct {
+	limiter *rate.Limiter
+	day     string
+	count   int64
+}
+
+// NewLimiter returns a limiter using the client limits, or the defaults for the clients without their own
+func NewLimiter(conf config.Limits) Limiter {
+	limits := map[string]config.Limit{}
+	for _, client := range conf.Clients {
+		limits[client.Client] = client.Limit
+	}
+	return &limiter{
+		defaults: conf.Default,
+		limits:   limits,
+		clients:  map[string]*clientUsage{},
+	}
+}
+
+// Allow count a request of the client, returning an error if it is over its limits
+func (l *limiter) Allow(client string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.limits[client]
+	if !ok {
+		limit = l.defaults
+	}
+	usage, ok := l.clients[client]
+	if !ok {
+		usage = &clientUsage{limiter: rate.NewLimiter(rate.Inf, 0)}
+		if limit.Rate > 0 {
+			usage.limiter = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+		}
+		l.clients[client] = usage
+	}
+
+	day := time.Now().UTC().Format("2006-01-02")
+	if usage.day != day {
+		usage.day = day
+		usage.count = 0
+	}
+	if limit.DailyQuota > 0 && usage.count >= limit.DailyQuota {
+		return ErrQuotaExceeded
+	}
+	if !usage.limiter.Allow() {
+		return ErrRateLimited
+	}
+	usage.count++
+	return nil
+}
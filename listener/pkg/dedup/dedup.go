@@ -0,0 +1,100 @@
+package dedup
+
+import (
+	"container/list"
+	"github.com/StevenRojas/natscrawler/common/pkg/rokuurl"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Window remembers the recently queued URLs by channel, so the duplicates are not queued again
+type Window interface {
+	// Add record the URL as queued by the request. If the URL was queued within the window it returns the request
+	// ID that queued it and true, unless force is set, then the URL is recorded again
+	Add(url string, requestID string, force bool) (string, bool)
+	// Remove forget the URL if it was recorded by the request, i.e.: it could not be queued
+	Remove(url string, requestID string)
+}
+
+type window struct {
+	length     time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	// entries ordered by queued time, the oldest at the back
+	entries *list.List
+	keys    map[string]*list.Element
+	// now returns the current time, replaced by the tests
+	now func() time.Time
+}
+
+type entry struct {
+	key       string
+	requestID string
+	queuedAt  time.Time
+}
+
+// NewWindow returns a window remembering each URL for its length, up to max entries. The oldest URLs are forgotten
+// first when it is full
+func NewWindow(length time.Duration, maxEntries int) Window {
+	return &window{
+		length:     length,
+		maxEntries: maxEntries,
+		entries:    list.New(),
+		keys:       map[string]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+func (w *window) Add(url string, requestID string, force bool) (string, bool) {
+	key := channelKey(url)
+	w.mu.Lock()
+	now := w.now()
+	defer w.mu.Unlock()
+	w.expire(now)
+	if element, ok := w.keys[key]; ok {
+		if !force {
+			return element.Value.(*entry).requestID, true
+		}
+		w.remove(element)
+	}
+	for w.entries.Len() >= w.maxEntries {
+		w.remove(w.entries.Back())
+	}
+	w.keys[key] = w.entries.PushFront(&entry{key: key, requestID: requestID, queuedAt: now})
+	return "", false
+}
+
+func (w *window) Remove(url string, requestID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	element, ok := w.keys[channelKey(url)]
+	if ok && element.Value.(*entry).requestID == requestID {
+		w.remove(element)
+	}
+}
+
+// expire forget the URLs queued before the window
+func (w *window) expire(now time.Time) {
+	for element := w.entries.Back(); element != nil; element = w.entries.Back() {
+		if now.Sub(element.Value.(*entry).queuedAt) < w.length {
+			return
+		}
+		w.remove(element)
+	}
+}
+
+func (w *window) remove(element *list.Element) {
+	w.entries.Remove(element)
+	delete(w.keys, element.Value.(*entry).key)
+}
+
+// channelKey returns the channel key of the URL, so the URLs of the same channel with different slugs match, or the
+// trimmed URL if it is not a channel store URL
+func channelKey(url string) string {
+	channelURL, err := rokuurl.Parse(url)
+	if err != nil {
+		return strings.TrimSpace(url)
+	}
+	return channelURL.Key()
+}
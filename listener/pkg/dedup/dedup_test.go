@@ -0,0 +1,131 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowAdd(t *testing.T) {
+	type add struct {
+		url       string
+		requestID string
+		force     bool
+		// after time elapsed since the window started
+		after         time.Duration
+		wantRequestID string
+		wantDuplicate bool
+	}
+	tests := []struct {
+		name       string
+		maxEntries int
+		adds       []add
+	}{
+		{
+			name:       "same URL within the window",
+			maxEntries: 10,
+			adds: []add{
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "1"},
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "2", after: time.Minute,
+					wantRequestID: "1", wantDuplicate: true},
+			},
+		},
+		{
+			name:       "same channel with another slug",
+			maxEntries: 10,
+			adds: []add{
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "1"},
+				{url: "https://channelstore.roku.com/details/12345/renamed-channel/", requestID: "2",
+					wantRequestID: "1", wantDuplicate: true},
+				{url: "https://channelstore.roku.com/details/12345", requestID: "3", wantRequestID: "1",
+					wantDuplicate: true},
+			},
+		},
+		{
+			name:       "same channel with another locale",
+			maxEntries: 10,
+			adds: []add{
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "1"},
+				{url: "https://channelstore.roku.com/en-gb/details/12345/channel", requestID: "2"},
+			},
+		},
+		{
+			name:       "same URL after the window",
+			maxEntries: 10,
+			adds: []add{
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "1"},
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "2", after: time.Hour},
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "3", after: time.Hour,
+					wantRequestID: "2", wantDuplicate: true},
+			},
+		},
+		{
+			name:       "forced URL",
+			maxEntries: 10,
+			adds: []add{
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "1"},
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "2", force: true},
+				{url: "https://channelstore.roku.com/details/12345/channel", requestID: "3",
+					wantRequestID: "2", wantDuplicate: true},
+			},
+		},
+		{
+			name:       "oldest URL forgotten when full",
+			maxEntries: 2,
+			adds: []add{
+				{url: "https://channelstore.roku.com/details/1", requestID: "1"},
+				{url: "https://channelstore.roku.com/details/2", requestID: "2"},
+				{url: "https://channelstore.roku.com/details/3", requestID: "3"},
+				{url: "https://channelstore.roku.com/details/2", requestID: "4", wantRequestID: "2",
+					wantDuplicate: true},
+				{url: "https://channelstore.roku.com/details/1", requestID: "5"},
+			},
+		},
+		{
+			name:       "other URLs by trimmed URL",
+			maxEntries: 10,
+			adds: []add{
+				{url: "https://example.com/app", requestID: "1"},
+				{url: " https://example.com/app ", requestID: "2", wantRequestID: "1", wantDuplicate: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			now := start
+			w := NewWindow(time.Hour, tt.maxEntries).(*window)
+			w.now = func() time.Time { return now }
+			for i, a := range tt.adds {
+				now = start.Add(a.after)
+				requestID, duplicate := w.Add(a.url, a.requestID, a.force)
+				if requestID != a.wantRequestID || duplicate != a.wantDuplicate {
+					t.Errorf("Add() #%d = %q, %v, want %q, %v", i, requestID, duplicate, a.wantRequestID,
+						a.wantDuplicate)
+				}
+			}
+		})
+	}
+}
+
+func TestWindowRemove(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		wantDuplicate bool
+	}{
+		{name: "by the request that added it", requestID: "1"},
+		{name: "by another request", requestID: "2", wantDuplicate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "https://channelstore.roku.com/details/12345/channel"
+			w := NewWindow(time.Hour, 10)
+			w.Add(url, "1", false)
+			w.Remove(url, tt.requestID)
+			_, duplicate := w.Add(url, "3", false)
+			if duplicate != tt.wantDuplicate {
+				t.Errorf("Add() after Remove() duplicate = %v, want %v", duplicate, tt.wantDuplicate)
+			}
+		})
+	}
+}
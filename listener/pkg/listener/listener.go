@@ -3,22 +3,39 @@ package listener
 import (
 	"context"
 	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/certs"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/common/pkg/natsconn"
 	"github.com/StevenRojas/natscrawler/grpcapi/pkg/crawler/pb"
 	"github.com/StevenRojas/natscrawler/listener/config"
+	"github.com/StevenRojas/natscrawler/listener/pkg/auth"
+	"github.com/StevenRojas/natscrawler/listener/pkg/dedup"
 	"github.com/golang/protobuf/proto"
 	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
-	"log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"math/rand"
 	"net"
 	"os"
+	"sync/atomic"
+	"time"
 )
 
 type crawlerServer struct {
 	pb.UnimplementedCrawlerServiceServer
 	natsClient *nats.Conn
 	topic string
-	urlCount int
+	cancelTopic string
+	urlCount int64
+	// dedup recently queued URLs, nil if the deduplication is disabled
+	dedup dedup.Window
+	// console show the received URLs count on the terminal instead of logging each URL
+	console bool
 }
 
 // ProcessUrl get a URL and send it to NATS queue to be processed by the crawler services
@@ -35,11 +52,37 @@ func (c *crawlerServer) ProcessUrl(ctx context.Context, request *pb.UrlRequest)
 		return nil, err
 	}
 
-	c.displayCount()
+	jobID := jobIDFromContext(ctx)
+	if c.dedup != nil {
+		if requestID, duplicated := c.dedup.Add(request.Url, request.RequestId, request.Force); duplicated {
+			logging.L().Debug("duplicated URL", "request_id", request.RequestId, "job_id", jobID,
+				"queued_request_id", requestID, "url", request.Url)
+			return &pb.UrlResponse{
+				RequestId: requestID,
+				Status:    pb.UrlResponse_STATUS_DUPLICATE,
+			}, nil
+		}
+	}
+	client := auth.ClientFromContext(ctx)
+	c.displayCount(request, jobID, client)
 
-	// publish encoded proto request to NATS
-	err = c.natsClient.Publish(c.topic, encoded)
+	// publish encoded proto request to NATS with the time it is queued, the job ID and client headers are only set
+	// if there are
+	msg := nats.NewMsg(c.topic)
+	msg.Data = encoded
+	msg.Header.Set(natsconn.SentAtHeader, time.Now().UTC().Format(time.RFC3339Nano))
+	if jobID != "" {
+		msg.Header.Set(logging.JobIDHeader, jobID)
+	}
+	if client != "" {
+		msg.Header.Set(natsconn.ClientHeader, client)
+	}
+	err = c.natsClient.PublishMsg(msg)
 	if err != nil {
+		if c.dedup != nil {
+			c.dedup.Remove(request.Url, request.RequestId)
+		}
+		logging.L().Error("unable to queue URL", "request_id", request.RequestId, "job_id", jobID, "error", err)
 		return nil, err
 	}
 
@@ -49,68 +92,192 @@ func (c *crawlerServer) ProcessUrl(ctx context.Context, request *pb.UrlRequest)
 	}, nil
 }
 
-func (c *crawlerServer) displayCount() {
-	c.urlCount++
-	if c.urlCount == 1 {
-		log.Print("\033[H\033[2J")
-		log.Print("Starting getting URLs to be send to the Queue")
+// CancelRequest broadcast the cancellation to the crawler services, which cancel the request if it is queued or
+// in-flight and it was queued by the same client
+func (c *crawlerServer) CancelRequest(ctx context.Context, request *pb.CancelRequest) (*pb.CancelResponse, error) {
+	if request.RequestId == "" {
+		return nil, status.Error(codes.InvalidArgument, "request ID is required")
+	}
+	encoded, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	client := auth.ClientFromContext(ctx)
+	msg := nats.NewMsg(c.cancelTopic)
+	msg.Data = encoded
+	msg.Header.Set(natsconn.SentAtHeader, time.Now().UTC().Format(time.RFC3339Nano))
+	if client != "" {
+		msg.Header.Set(natsconn.ClientHeader, client)
+	}
+	err = c.natsClient.PublishMsg(msg)
+	if err != nil {
+		return &pb.CancelResponse{
+			RequestId: request.RequestId,
+			Status:    pb.CancelResponse_STATUS_UNAVAILABLE,
+		}, nil
+	}
+	logging.L().Info("cancel request sent", "request_id", request.RequestId, "client", client)
+	return &pb.CancelResponse{
+		RequestId: request.RequestId,
+		Status:    pb.CancelResponse_STATUS_ACCEPTED,
+	}, nil
+}
+
+// displayCount show the received URLs count on the interactive console, or log the received URL
+func (c *crawlerServer) displayCount(request *pb.UrlRequest, jobID string, client string) {
+	count := atomic.AddInt64(&c.urlCount, 1)
+	if !c.console {
+		logging.L().Debug("URL received", "request_id", request.RequestId, "job_id", jobID, "client", client,
+			"url", request.Url, "count", count)
+		return
+	}
+	if count == 1 {
+		fmt.Print("\033[H\033[2J")
+		fmt.Println("Starting getting URLs to be send to the Queue")
+	}
+	fmt.Printf("\033[4;4HURL received: %d\n", count)
+}
+
+// jobIDFromContext returns the job ID sent by the client, if any
+func jobIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
 	}
-	log.Printf("\033[4;4H")
-	log.Printf("URL received: %d", c.urlCount)
+	values := md.Get(logging.JobIDMetadata)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Listener interface
+type Listener interface {
+	Run(ctx context.Context) error
+}
+
+type listenerService struct {
+	natsClient      *nats.Conn
+	server          *grpc.Server
+	health          *health.Server
+	listener        net.Listener
+	address         string
+	shutdownTimeout time.Duration
 }
 
-// NewListener creates a new listener instance
-func NewListener(conf config.AppConfig) error {
-	nc, err := connectToNats(conf)
+// NewListener creates a new listener instance, owning the GRPC server and the NATS connection
+func NewListener(conf config.AppConfig) (Listener, error) {
+	healthServer := health.NewServer()
+	nc, err := natsconn.Connect(conf.Nats, conf.Grpc.ShutdownTimeout, natsconn.Handlers{
+		// the URLs can not be queued while the connection is down
+		Disconnected: func(_ *nats.Conn, _ error) {
+			setServingStatus(healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+		},
+		Reconnected: func(_ *nats.Conn) {
+			setServingStatus(healthServer, healthpb.HealthCheckResponse_SERVING)
+		},
+		Closed: func(_ *nats.Conn) {
+			setServingStatus(healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+		},
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_SERVING)
 	crawler := &crawlerServer{
 		natsClient: nc,
 		topic: conf.Queue.Topic,
+		cancelTopic: conf.Queue.CancelTopic,
+		console: conf.Log.Console && logging.IsTerminal(os.Stdout),
+	}
+	if conf.Dedup.Window > 0 {
+		crawler.dedup = dedup.NewWindow(conf.Dedup.Window, conf.Dedup.MaxEntries)
 	}
-	err = startGRPCServer(conf, crawler)
+	server, listener, err := startGRPCServer(conf, crawler, healthServer)
 	if err != nil {
-		return err
+		nc.Close()
+		return nil, err
 	}
-	log.SetOutput(os.Stdout)
-	log.SetFlags(log.Lmsgprefix)
-	return nil
+	return &listenerService{
+		natsClient:      nc,
+		server:          server,
+		health:          healthServer,
+		listener:        listener,
+		address:         conf.Grpc.Address,
+		shutdownTimeout: conf.Grpc.ShutdownTimeout,
+	}, nil
 }
 
-// connectToNats connect to NATS server
-func connectToNats(conf config.AppConfig) (*nats.Conn, error) {
-	opts := nats.Options{
-		Url:            conf.Nats.Host,
-		AllowReconnect: conf.Nats.AllowReconnect,
-		MaxReconnect:   conf.Nats.MaxReconnectAttempts,
-		ReconnectWait:  conf.Nats.ReconnectWait,
-		Timeout:        conf.Nats.Timeout,
+// Run serve GRPC requests until the context is done or the server fails. On shutdown the server is gracefully
+// stopped and the NATS connection is drained, so the accepted URLs are published before exit
+func (l *listenerService) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- l.server.Serve(l.listener)
+	}()
+	logging.L().Info("starting GRPC server", "address", l.address)
+
+	select {
+	case err := <-serveErr:
+		l.natsClient.Close()
+		return fmt.Errorf("unable to start GRPC server: %w", err)
+	case <-ctx.Done():
 	}
 
-	nc, err := opts.Connect()
+	logging.L().Info("stopping GRPC server")
+	l.health.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		l.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(l.shutdownTimeout):
+		logging.L().Warn("GRPC graceful stop timed out, closing pending connections")
+		l.server.Stop()
+	}
+
+	logging.L().Info("draining NATS connection")
+	err := natsconn.Drain(l.natsClient)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("unable to drain NATS connection: %w", err)
 	}
-	fmt.Println("Connected to NATS at:", nc.ConnectedUrl())
-	return nc, nil
+	logging.L().Info("listener stopped")
+	return nil
 }
 
-// startGRPCServer starts the GRPC server
-func startGRPCServer(conf config.AppConfig, crawler *crawlerServer) error {
-	server := grpc.NewServer()
+// setServingStatus set the status of the overall server and the crawler service
+func setServingStatus(healthServer *health.Server, status healthpb.HealthCheckResponse_ServingStatus) {
+	healthServer.SetServingStatus("", status)
+	healthServer.SetServingStatus(pb.CrawlerService_ServiceDesc.ServiceName, status)
+}
+
+// startGRPCServer creates the GRPC server and its network listener
+func startGRPCServer(conf config.AppConfig, crawler *crawlerServer, healthServer *health.Server) (*grpc.Server, net.Listener, error) {
+	var opts []grpc.ServerOption
+	if conf.Grpc.TLS.Enabled {
+		tlsConf := conf.Grpc.TLS
+		store, err := certs.NewStore(tlsConf.CertFile, tlsConf.KeyFile, tlsConf.ClientCAFile, tlsConf.ReloadInterval)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to load GRPC server certificates: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(store.ServerConfig(tlsConf.ClientCAFile != ""))))
+	}
+	if conf.Auth.Enabled {
+		interceptor, err := auth.NewInterceptor(conf.Auth)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to set GRPC authentication: %w", err)
+		}
+		opts = append(opts, grpc.ChainUnaryInterceptor(interceptor.Unary()), grpc.ChainStreamInterceptor(interceptor.Stream()))
+	}
+	server := grpc.NewServer(opts...)
 	listener, err := net.Listen("tcp", conf.Grpc.Address)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	pb.RegisterCrawlerServiceServer(server, crawler)
-	go func() {
-		err := server.Serve(listener)
-		if err != nil {
-			log.Panicf("unable to start GRPC server: %s\n", err.Error())
-		}
-	}()
-	fmt.Printf("Starting GRPC server at %s\n", conf.Grpc.Address)
-	return nil
-}
\ No newline at end of file
+	healthpb.RegisterHealthServer(server, healthServer)
+	return server, listener, nil
+}
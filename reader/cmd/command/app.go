@@ -2,26 +2,31 @@ package command
 
 import (
 	"context"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/StevenRojas/natscrawler/reader/pkg/checkpoint"
 	"github.com/StevenRojas/natscrawler/reader/pkg/parser"
+	"github.com/StevenRojas/natscrawler/reader/pkg/progress"
+	"github.com/StevenRojas/natscrawler/reader/pkg/results"
 	"github.com/StevenRojas/natscrawler/reader/pkg/sender"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+	"os"
 	"sync"
 )
 
 // NewRootCommand creates the root command
 func NewRootCommand(ctx context.Context) *cobra.Command {
-	var csvFile string
-	rootCommand := &cobra.Command{
-		Use:   "process",
-		Short: "Parse and process a CSV file",
-
-		PersistentPreRunE: config.Setup,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return process(ctx, csvFile)
-		},
+	rootCommand := NewCommand(ctx)
+	rootCommand.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		err := config.Setup(cmd, args)
+		if err != nil {
+			return err
+		}
+		return logging.Setup(logging.Output(config.App.Log), config.App.Log, "service", "reader")
 	}
-
 	rootCommand.Flags().StringVarP(
 		&config.Filename,
 		"config",
@@ -30,30 +35,138 @@ func NewRootCommand(ctx context.Context) *cobra.Command {
 		"Relative path to the config file",
 	)
 
-	rootCommand.Flags().StringVarP(&csvFile, "file", "f", "", "Path to CSV file")
-	rootCommand.MarkFlagRequired("file")
-
 	return rootCommand
 }
 
-// process csv file
-func process(ctx context.Context, csvFile string) error {
-	s, err := sender.NewGrpcService(ctx, config.App.Grpc)
+// NewCommand creates the command that sends the CSV file URLs to the listener. The configuration is expected to be
+// loaded by the caller, so it can be used by other CLIs
+func NewCommand(ctx context.Context) *cobra.Command {
+	var csvFile string
+	var token string
+	var checkpointFile string
+	var resume bool
+	var jobID string
+	var force bool
+	command := &cobra.Command{
+		Use:   "process",
+		Short: "Parse and process a CSV file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token != "" {
+				config.App.Grpc.Token = token
+			}
+			if force {
+				config.App.General.Force = true
+			}
+			if checkpointFile == "" {
+				checkpointFile = csvFile + ".checkpoint"
+			}
+			return process(ctx, csvFile, checkpointFile, resume, jobID)
+		},
+	}
+
+	command.Flags().StringVarP(&csvFile, "file", "f", "", "Path to CSV file")
+	command.MarkFlagRequired("file")
+	command.Flags().StringVar(&token, "token", "", "Bearer token used to authenticate with the listener")
+	command.Flags().StringVar(&checkpointFile, "checkpoint", "", "Path to the checkpoint file, the CSV file path "+
+		"with the .checkpoint extension by default")
+	command.Flags().BoolVar(&resume, "resume", false, "Resume the run of the checkpoint, skipping the accepted rows")
+	command.Flags().BoolVar(&force, "force", false, "Queue the URLs even if the listener recently queued them")
+	command.Flags().StringVar(&jobID, "job-id", "", "Job ID of the run, a random UUID by default. Set it to "+
+		"reproduce the request IDs derived from the URLs")
+	config.AddFlags(command)
+
+	return command
+}
+
+// process csv file, the URLs are sent with the job ID of the run, so their logs can be correlated. The acknowledged
+// rows are saved to the checkpoint file, a resumed run keeps the job ID and skips the accepted rows
+func process(ctx context.Context, csvFile string, checkpointFile string, resume bool, jobID string) error {
+	if jobID == "" && !resume {
+		jobID = uuid.New().String()
+	}
+	cp, err := checkpoint.New(checkpointFile, csvFile, jobID, resume)
 	if err != nil {
 		return err
 	}
-	p, err := parser.NewCSVParser(csvFile, config.App.General.SkipRows, config.App.General.URLDomain)
+	jobID = cp.JobID()
+	ctx = metadata.AppendToOutgoingContext(ctx, logging.JobIDMetadata, jobID)
+	ctx = logging.NewContext(ctx, logging.L().With("job_id", jobID))
+	if resume {
+		logging.FromContext(ctx).Info("resuming CSV file", "file", csvFile, "checkpoint", checkpointFile,
+			"offset", cp.Offset())
+	} else {
+		logging.FromContext(ctx).Info("processing CSV file", "file", csvFile, "checkpoint", checkpointFile)
+	}
+
+	tracker := progress.NewTracker()
+	s, err := sender.NewGrpcService(ctx, config.App.Grpc, config.App.General, tracker, cp)
 	if err != nil {
 		return err
 	}
+	p, err := parser.NewCSVParser(csvFile, config.App.General, tracker)
+	if err != nil {
+		return err
+	}
+	total, err := p.Rows()
+	if err != nil {
+		return err
+	}
+	tracker.SetTotal(total)
 
-	urlCh := make(chan string, config.App.General.BufferSize)
-
+	reportCtx, stopReport := context.WithCancel(ctx)
+	defer stopReport()
 	wg := sync.WaitGroup{}
+	if config.App.Results.Enabled {
+		counter, err := results.NewCounter(config.App.Results.Database)
+		if err != nil {
+			return fmt.Errorf("unable to connect to the results database: %w", err)
+		}
+		defer counter.Close()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter.Watch(reportCtx, jobID, tracker, config.App.Results.Interval)
+		}()
+	}
+	console := config.App.Log.Console && logging.IsTerminal(os.Stdout)
+	reporter := progress.NewReporter(tracker, os.Stdout, console, config.App.General.ProgressInterval)
 	wg.Add(2)
-	go s.Start(ctx, &wg, urlCh)
-	go p.Parse(ctx, &wg, urlCh)
-	wg.Wait()
+	go func() {
+		defer wg.Done()
+		reporter.Run(reportCtx)
+	}()
+	go func() {
+		defer wg.Done()
+		cp.Run(reportCtx)
+	}()
 
-	return nil
-}
\ No newline at end of file
+	// the parser and the sender are stopped if any of them fails
+	sendCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rowCh := make(chan parser.Row, config.App.General.BufferSize)
+	var parseErr, sendErr error
+	sendWg := sync.WaitGroup{}
+	sendWg.Add(2)
+	go func() {
+		defer sendWg.Done()
+		sendErr = s.Start(sendCtx, rowCh)
+		if sendErr != nil {
+			cancel()
+		}
+	}()
+	go func() {
+		defer sendWg.Done()
+		parseErr = p.Parse(sendCtx, rowCh)
+		if parseErr != nil {
+			cancel()
+		}
+	}()
+	sendWg.Wait()
+
+	stopReport()
+	wg.Wait()
+	if parseErr != nil {
+		return parseErr
+	}
+	return sendErr
+}
@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/reader/cmd/command"
-	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -18,17 +18,17 @@ func main() {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
 	defer func() {
-		log.Println("closing application")
+		logging.L().Info("closing application")
 		signal.Stop(signals)
 		cancel()
 	}()
 
 	go func() {
 		<-signals
-		log.Println("propagating cancel signal")
+		logging.L().Info("propagating cancel signal")
 		cancel()
 		time.Sleep(closeFallbackTime * time.Second)
-		log.Println("fallback exit")
+		logging.L().Warn("fallback exit")
 		os.Exit(1)
 	}()
 
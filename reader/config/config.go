@@ -1,34 +1,38 @@
 package config
 
 import (
+	"github.com/StevenRojas/natscrawler/common/pkg/appconfig"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
-	"strings"
 	"time"
 )
 
 // environmentPrefix prefix used to avoid environment variable names collisions
 const environmentPrefix = "CT_R"
 
+// Request IDs sources
+const (
+	// RequestIDsRandom a random UUID per URL
+	RequestIDsRandom = "random"
+	// RequestIDsURL a UUID derived from the job ID and the channel of the URL, so it is reproduced by the same job
+	RequestIDsURL = "url"
+	// RequestIDsColumn the request IDs are read from a CSV file column
+	RequestIDsColumn = "column"
+)
+
 var (
 	// Filename configuration file name.
 	Filename string
 	// App configuration struct
 	App AppConfig
-
-	// environmentVarList list of environment variables read by the app. The name should match with a struct field.
-	// The dots will be replaced by underscores, it will be capitalized and the environmentPrefix will be added
-	// 		i.e.: nats.host => CT_R_NATS_HOST
-	environmentVarList = []string{
-		"nats.host",
-	}
 )
 
 // AppConfig struct
 type AppConfig struct {
-	General General    `mapstructure:"general"`
-	Grpc    GrpcServer `mapstructure:"grpc"`
+	General General        `mapstructure:"general"`
+	Grpc    GrpcServer     `mapstructure:"grpc"`
+	Results Results        `mapstructure:"results"`
+	Log     logging.Config `mapstructure:"log"`
 }
 
 // General configuration
@@ -36,74 +40,144 @@ type General struct {
 	SkipRows   int    `mapstructure:"skip_rows"`
 	BufferSize int    `mapstructure:"buffer_size"`
 	URLDomain  string `mapstructure:"url_domain"`
+	// ProgressInterval time between the progress log lines, used when the output is not a terminal
+	ProgressInterval time.Duration `mapstructure:"progress_interval"`
+	// RequestIDs source of the request IDs: random, url or column
+	RequestIDs      string `mapstructure:"request_ids"`
+	RequestIDColumn int    `mapstructure:"request_id_column"`
+	// Deduplicate send only the first row of each channel, whatever the URL slug
+	Deduplicate bool `mapstructure:"deduplicate"`
+	// Force ask the listener to queue the URLs even if they were recently queued
+	Force bool `mapstructure:"force"`
 }
 
 // GrpcServer GRPC server configuration
 type GrpcServer struct {
-	ListenerAddress        string `mapstructure:"listener_address"`
-	DialTimeoutDuration    string `mapstructure:"dial_timeout"`
-	RequestTimeoutDuration string `mapstructure:"request_timeout"`
-	PingIntervalDuration   string `mapstructure:"ping_interval"`
-	PingTimeoutDuration    string `mapstructure:"ping_timeout"`
-	DialTimeout            time.Duration
-	RequestTimeout         time.Duration
-	PingInterval           time.Duration
-	PingTimeout            time.Duration
+	ListenerAddress string        `mapstructure:"listener_address"`
+	DialTimeout     time.Duration `mapstructure:"dial_timeout"`
+	RequestTimeout  time.Duration `mapstructure:"request_timeout"`
+	PingInterval    time.Duration `mapstructure:"ping_interval"`
+	PingTimeout     time.Duration `mapstructure:"ping_timeout"`
+	// MaxRetries times a URL is sent again when the listener asks for a retry or limits the client
+	MaxRetries   int           `mapstructure:"max_retries"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	// Token bearer token sent to the listener, an API key or a JWT
+	Token string    `mapstructure:"token" secret:"true"`
+	TLS   ClientTLS `mapstructure:"tls"`
+}
+
+// ClientTLS GRPC client TLS configuration, the files are reloaded when they change
+type ClientTLS struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CAFile CA bundle used to verify the listener certificate, the system roots are used if it is empty
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile and KeyFile client certificate, required if the listener verifies the clients (mTLS)
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ServerName overrides the name verified in the listener certificate, the listener address host is used if empty
+	ServerName     string        `mapstructure:"server_name"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// Results crawl results database, polled to show the succeeded and failed URLs of the run
+type Results struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Database Database      `mapstructure:"database"`
+}
+
+// Database database configuration
+type Database struct {
+	Host     string `mapstructure:"host"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password" secret:"true"`
+	Database string `mapstructure:"database"`
+	Port     int    `mapstructure:"port"`
+	SSLMode  string `mapstructure:"ssl_mode"`
+}
+
+// AddFlags add a flag overriding each configuration key
+func AddFlags(cmd *cobra.Command) {
+	appconfig.AddFlags(cmd.Flags(), environmentPrefix, &App)
 }
 
-// Setup bind command flags and environment variables
-// The precedence to override a configuration is: flag -> environment variable -> configuration field
+// Setup load the configuration file, overridden by the command flags and environment variables
 func Setup(cmd *cobra.Command, _ []string) error {
-	v := viper.New()
-	v.SetConfigFile(Filename)
-	v.SetConfigType("yaml")
-	v.AddConfigPath("./config")
-
-	v.SetEnvPrefix(environmentPrefix)
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	err := v.ReadInConfig()
-	if err != nil {
-		return err
-	}
+	return appconfig.Load(cmd, Filename, environmentPrefix, &App)
+}
 
-	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
-		_ = v.BindPFlag(flag.Name, cmd.Flags().Lookup(flag.Name))
-	})
-	for _, env := range environmentVarList {
-		_ = v.BindPFlag(env, cmd.Flags().Lookup(env))
-	}
+// Validate the configuration
+func (c *AppConfig) Validate() error {
+	var errs appconfig.Errors
+	errs.Merge("log", c.Log.Validate())
+	errs.Merge("general", c.General.Validate())
+	errs.Merge("grpc", c.Grpc.Validate())
+	errs.Merge("results", c.Results.Validate())
+	return errs.Err()
+}
 
-	err = v.Unmarshal(&App)
-	if err != nil {
-		return err
+// Validate the general configuration
+func (g General) Validate() error {
+	var errs appconfig.Errors
+	if g.SkipRows < 0 {
+		errs.Add("skip_rows", "can not be negative")
+	}
+	if g.BufferSize < 0 {
+		errs.Add("buffer_size", "can not be negative")
 	}
-	err = parseDurations()
-	if err != nil {
-		return err
+	if g.URLDomain != "" {
+		errs.URL("url_domain", g.URLDomain, "http", "https")
 	}
-	return nil
+	errs.Positive("progress_interval", g.ProgressInterval)
+	errs.OneOf("request_ids", g.RequestIDs, RequestIDsRandom, RequestIDsURL, RequestIDsColumn)
+	if g.RequestIDs == RequestIDsColumn && g.RequestIDColumn < 1 {
+		errs.Add("request_id_column", "must be greater than 0, the first column is the URL")
+	}
+	return errs.Err()
 }
 
-// parseDurations parse string durations (1s, 2h, etc.) into time.Duration values
-func parseDurations() error {
-	var err error
-	App.Grpc.DialTimeout, err = time.ParseDuration(App.Grpc.DialTimeoutDuration)
-	if err != nil {
-		return err
+// Validate the GRPC client configuration
+func (g GrpcServer) Validate() error {
+	var errs appconfig.Errors
+	errs.HostPort("listener_address", g.ListenerAddress)
+	errs.Positive("dial_timeout", g.DialTimeout)
+	errs.NotNegative("request_timeout", g.RequestTimeout)
+	errs.NotNegative("ping_interval", g.PingInterval)
+	errs.NotNegative("ping_timeout", g.PingTimeout)
+	if g.MaxRetries < 0 {
+		errs.Add("max_retries", "can not be negative")
 	}
-	App.Grpc.RequestTimeout, err = time.ParseDuration(App.Grpc.RequestTimeoutDuration)
-	if err != nil {
-		return err
+	errs.NotNegative("retry_backoff", g.RetryBackoff)
+	if g.Token != "" && !g.TLS.Enabled {
+		errs.Add("token", "requires tls.enabled")
 	}
-	App.Grpc.PingTimeout, err = time.ParseDuration(App.Grpc.PingTimeoutDuration)
-	if err != nil {
-		return err
+	if g.TLS.Enabled {
+		if (g.TLS.CertFile == "") != (g.TLS.KeyFile == "") {
+			errs.Add("tls.key_file", "cert_file and key_file must be set together")
+		}
+		errs.NotNegative("tls.reload_interval", g.TLS.ReloadInterval)
 	}
-	App.Grpc.PingInterval, err = time.ParseDuration(App.Grpc.PingIntervalDuration)
-	if err != nil {
-		return err
+	return errs.Err()
+}
+
+// Validate the results configuration, only if it is enabled
+func (r Results) Validate() error {
+	if !r.Enabled {
+		return nil
 	}
-	return nil
+	var errs appconfig.Errors
+	errs.Positive("interval", r.Interval)
+	errs.Merge("database", r.Database.Validate())
+	return errs.Err()
+}
+
+// Validate the database configuration
+func (d Database) Validate() error {
+	var errs appconfig.Errors
+	errs.Required("host", d.Host)
+	errs.Required("database", d.Database)
+	errs.Required("username", d.Username)
+	errs.Range("port", d.Port, 1, 65535)
+	errs.OneOf("ssl_mode", d.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	return errs.Err()
 }
@@ -3,8 +3,10 @@ module github.com/StevenRojas/natscrawler/reader
 go 1.18
 
 require (
+	github.com/StevenRojas/natscrawler/common v0.0.0
 	github.com/StevenRojas/natscrawler/grpcapi v0.0.0
 	github.com/google/uuid v1.1.2
+	github.com/lib/pq v1.10.4
 	github.com/spf13/cobra v1.4.0
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.10.1
@@ -33,3 +35,5 @@ require (
 )
 
 replace github.com/StevenRojas/natscrawler/grpcapi v0.0.0 => ../grpcapi
+
+replace github.com/StevenRojas/natscrawler/common v0.0.0 => ../common
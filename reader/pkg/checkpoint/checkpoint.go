@@ -0,0 +1,209 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// saveInterval time between the checkpoint saves while the file is processed
+const saveInterval = time.Second
+
+// Checkpoint progress of a CSV file submission, saved to a file so an interrupted run can be resumed
+type Checkpoint interface {
+	// JobID job of the run, the one of the resumed run if it is resumed
+	JobID() string
+	// Offset last acknowledged row
+	Offset() int
+	// Accepted returns the request ID of the row if it was already accepted
+	Accepted(row int) (string, bool)
+	// Duplicated returns the request ID that queued the row URL if the listener reported it as duplicated
+	Duplicated(row int) (string, bool)
+	// Ack record the listener response to a row, the request ID is kept only if it was accepted
+	Ack(row int, requestID string, accepted bool)
+	// AckDuplicated record a row duplicated by the listener with the request ID that queued its URL, which is
+	// usually from another job
+	AckDuplicated(row int, queuedRequestID string)
+	// Run save the checkpoint periodically until the context is done, then it is saved for the last time
+	Run(ctx context.Context)
+	Save() error
+}
+
+// state checkpoint file content
+type state struct {
+	JobID string `json:"job_id"`
+	File  string `json:"file"`
+	// Offset last acknowledged row, the rows are acknowledged in order
+	Offset int `json:"offset"`
+	// Requests request ID of each accepted row
+	Requests map[int]string `json:"requests"`
+	// Duplicates request ID that queued the URL of each duplicated row, their results are not part of the job
+	Duplicates map[int]string `json:"duplicates,omitempty"`
+}
+
+type checkpoint struct {
+	path  string
+	mu    sync.Mutex
+	state state
+	dirty bool
+}
+
+// New returns a checkpoint of the CSV file that is saved to path. If resume is set the checkpoint is loaded from
+// path, and it must belong to the job ID if it is set, otherwise a new one is started with the job ID. The file is
+// recorded with its absolute path, so the resumed run can refer to it with any relative path
+func New(path string, file string, jobID string, resume bool) (Checkpoint, error) {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve the path of %s: %w", file, err)
+	}
+	c := &checkpoint{
+		path: path,
+		state: state{
+			JobID:      jobID,
+			File:       absFile,
+			Requests:   map[int]string{},
+			Duplicates: map[int]string{},
+		},
+	}
+	if !resume {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read checkpoint: %w", err)
+	}
+	err = json.Unmarshal(data, &c.state)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode checkpoint %s: %w", path, err)
+	}
+	// checkpoints could be saved with a relative path, which is relative to the working directory
+	if stored, err := filepath.Abs(c.state.File); err != nil || stored != absFile {
+		return nil, fmt.Errorf("checkpoint %s belongs to file %s", path, c.state.File)
+	}
+	if c.state.JobID == "" {
+		return nil, errors.New("checkpoint without job ID")
+	}
+	if jobID != "" && c.state.JobID != jobID {
+		return nil, fmt.Errorf("checkpoint %s belongs to job %s", path, c.state.JobID)
+	}
+	if c.state.Requests == nil {
+		c.state.Requests = map[int]string{}
+	}
+	if c.state.Duplicates == nil {
+		c.state.Duplicates = map[int]string{}
+	}
+	return c, nil
+}
+
+func (c *checkpoint) JobID() string {
+	return c.state.JobID
+}
+
+func (c *checkpoint) Offset() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.Offset
+}
+
+func (c *checkpoint) Accepted(row int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	requestID, ok := c.state.Requests[row]
+	return requestID, ok
+}
+
+func (c *checkpoint) Duplicated(row int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	requestID, ok := c.state.Duplicates[row]
+	return requestID, ok
+}
+
+func (c *checkpoint) Ack(row int, requestID string, accepted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ack(row)
+	if accepted {
+		c.state.Requests[row] = requestID
+	}
+}
+
+func (c *checkpoint) AckDuplicated(row int, queuedRequestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ack(row)
+	c.state.Duplicates[row] = queuedRequestID
+}
+
+// ack move the offset to the row, the lock must be held
+func (c *checkpoint) ack(row int) {
+	if row > c.state.Offset {
+		c.state.Offset = row
+	}
+	c.dirty = true
+}
+
+func (c *checkpoint) Run(ctx context.Context) {
+	ticker := time.NewTicker(saveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.Save(); err != nil {
+				logging.FromContext(ctx).Error("unable to save checkpoint", "file", c.path, "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := c.Save(); err != nil {
+				logging.FromContext(ctx).Warn("unable to save checkpoint", "file", c.path, "error", err)
+			}
+		}
+	}
+}
+
+// Save write the checkpoint if it changed
+func (c *checkpoint) Save() error {
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(c.state)
+	c.dirty = false
+	c.mu.Unlock()
+	if err == nil {
+		err = c.write(data)
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// write the data to a temporary file that is renamed, so the checkpoint is never left truncated
+func (c *checkpoint) write(data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,149 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// saved returns the path of a checkpoint of data.csv saved with an accepted, a rejected and a duplicated row
+func saved(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	c, err := New(path, "data.csv", "job-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Ack(1, "request-1", true)
+	c.Ack(2, "request-2", false)
+	c.AckDuplicated(3, "request-0")
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return path
+}
+
+func TestNewResume(t *testing.T) {
+	absFile, err := filepath.Abs("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		file    string
+		jobID   string
+		wantErr bool
+	}{
+		{name: "same file and job", file: "data.csv", jobID: "job-1"},
+		{name: "job of the checkpoint", file: "data.csv"},
+		{name: "relative path with dot", file: "./data.csv"},
+		{name: "absolute path", file: absFile},
+		{name: "unclean path", file: "../checkpoint/data.csv"},
+		{name: "other file", file: "other.csv", wantErr: true},
+		{name: "other job", file: "data.csv", jobID: "job-2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(saved(t), tt.file, tt.jobID, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if c.JobID() != "job-1" || c.Offset() != 3 {
+				t.Errorf("New() job %q offset %d, want job-1 offset 3", c.JobID(), c.Offset())
+			}
+		})
+	}
+}
+
+func TestNewResumeMissing(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), "data.csv", "", true)
+	if err == nil {
+		t.Error("New() error = nil, want an error for a missing checkpoint")
+	}
+}
+
+func TestResumedRows(t *testing.T) {
+	c, err := New(saved(t), "data.csv", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		row            int
+		wantAccepted   string
+		wantDuplicated string
+	}{
+		{row: 1, wantAccepted: "request-1"},
+		{row: 2},
+		{row: 3, wantDuplicated: "request-0"},
+		{row: 4},
+	}
+	for _, tt := range tests {
+		accepted, ok := c.Accepted(tt.row)
+		if accepted != tt.wantAccepted || ok != (tt.wantAccepted != "") {
+			t.Errorf("Accepted(%d) = %q %v, want %q", tt.row, accepted, ok, tt.wantAccepted)
+		}
+		duplicated, ok := c.Duplicated(tt.row)
+		if duplicated != tt.wantDuplicated || ok != (tt.wantDuplicated != "") {
+			t.Errorf("Duplicated(%d) = %q %v, want %q", tt.row, duplicated, ok, tt.wantDuplicated)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoint.json")
+	if err := os.WriteFile(path, []byte("previous"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(path, "data.csv", "job-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nothing changed, the previous file is kept
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous" {
+		t.Errorf("checkpoint = %q, want it unchanged", data)
+	}
+
+	c.Ack(1, "request-1", true)
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "checkpoint.json" {
+		t.Errorf("checkpoint directory has %v, want only the checkpoint without temporary files", entries)
+	}
+	if _, err := New(path, "data.csv", "job-1", true); err != nil {
+		t.Errorf("New() error = %v, want the saved checkpoint", err)
+	}
+}
+
+func TestSaveRetried(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	path := filepath.Join(dir, "checkpoint.json")
+	c, err := New(path, "data.csv", "job-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Ack(1, "request-1", true)
+	if err := c.Save(); err == nil {
+		t.Fatal("Save() error = nil, want an error for a missing directory")
+	}
+	// the checkpoint is still pending, so it is saved once the directory exists
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("checkpoint not saved: %v", err)
+	}
+}
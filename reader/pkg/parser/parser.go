@@ -3,19 +3,35 @@ package parser
 import (
 	"context"
 	"encoding/csv"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/common/pkg/rokuurl"
+	"github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/StevenRojas/natscrawler/reader/pkg/progress"
 	"io"
-	"log"
 	"net/url"
 	"os"
 	"strings"
-	"sync"
 )
 
 const urlColNum = 0
 
+// Row valid URL of a CSV file
+type Row struct {
+	// Number position of the row in the file, starting at 1
+	Number int
+	URL    string
+	// Channel key of the URL, the locale and the channel ID, used to find the duplicated URLs whatever their slug
+	Channel string
+	// RequestID request ID read from the CSV file, if there is a request ID column
+	RequestID string
+}
+
 // CSVParser interface
 type CSVParser interface {
-	Parse(ctx context.Context, wg*sync.WaitGroup, urlCh chan<- string)
+	// Rows count the rows to be parsed, without the skipped ones
+	Rows() (int64, error)
+	Parse(ctx context.Context, rowCh chan<- Row) error
 }
 
 type csvParser struct {
@@ -23,70 +39,135 @@ type csvParser struct {
 	f *os.File
 	skipRows int
 	urlDomain string
+	deduplicate bool
+	// requestIDColumn column of the request IDs, -1 if they are not read from the file
+	requestIDColumn int
+	tracker progress.Tracker
 }
 
 // NewCSVParser validate that the CSV file exists and returns an instance of CSVParser
-func NewCSVParser(file string, skipRows int, urlDomain string) (CSVParser, error) {
+func NewCSVParser(file string, conf config.General, tracker progress.Tracker) (CSVParser, error) {
 	f, err := os.Open(file)
 	if err != nil {
-		log.Printf("unable to open file handler: %s\n", err.Error())
+		logging.L().Error("unable to open file handler", "file", file, "error", err)
 		return nil, err
 	}
+	requestIDColumn := -1
+	if conf.RequestIDs == config.RequestIDsColumn {
+		requestIDColumn = conf.RequestIDColumn
+	}
 	return &csvParser{
 		file: file,
 		f: f,
-		skipRows: skipRows,
-		urlDomain: urlDomain,
+		skipRows: conf.SkipRows,
+		urlDomain: conf.URLDomain,
+		deduplicate: conf.Deduplicate,
+		requestIDColumn: requestIDColumn,
+		tracker: tracker,
 	}, nil
 }
 
-// Parse a CSV file sending each row though the channel
-func (c *csvParser) Parse(ctx context.Context, wg*sync.WaitGroup, urlCh chan<- string) {
-	defer wg.Done()
-	log.Println("parsing CSV file")
+// Rows count the rows to be parsed reading the whole file, then it is rewound to be parsed
+func (c *csvParser) Rows() (int64, error) {
+	reader := csv.NewReader(c.f)
+	var count int64
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	_, err := c.f.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	count -= int64(c.skipRows)
+	if count < 0 {
+		return 0, nil
+	}
+	return count, nil
+}
+
+// Parse a CSV file sending each valid row though the channel, which is closed when the file is parsed
+func (c *csvParser) Parse(ctx context.Context, rowCh chan<- Row) error {
+	defer close(rowCh)
+	defer c.f.Close()
+	logger := logging.FromContext(ctx).With("file", c.file)
+	logger.Info("parsing CSV file")
 	reader := csv.NewReader(c.f)
 	count := 0
+	// seen first row of each channel
+	seen := map[string]int{}
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		default:
 		}
 		row, err := reader.Read()
 		if err == io.EOF {
-			log.Println("CSV end of file")
-			break
+			logger.Info("CSV end of file", "rows", count)
+			return nil
 		}
 		if err != nil {
-			log.Fatal(err)
+			return fmt.Errorf("unable to read CSV file: %w", err)
 		}
 		count++
 		if count <= c.skipRows {
 			continue
 		}
-		uri := row[urlColNum]
-		if c.validate(uri) {
-			urlCh <- uri
-		} else {
-			log.Printf("Invalid URL: %s\n", uri)
+		c.tracker.Parsed()
+		parsed, err := c.parseRow(count, row)
+		if err != nil {
+			c.tracker.Invalid()
+			logger.Warn("invalid URL", "row", count, "error", err)
+			continue
+		}
+		if c.deduplicate {
+			if first, ok := seen[parsed.Channel]; ok {
+				c.tracker.Duplicated()
+				logger.Info("duplicated URL", "row", count, "first_row", first, "url", parsed.URL)
+				continue
+			}
+			seen[parsed.Channel] = count
+		}
+		select {
+		case rowCh <- parsed:
+		case <-ctx.Done():
+			return nil
 		}
-
 	}
-	close(urlCh)
-	err := c.f.Close()
+}
+
+// parseRow validate the row URL and read its request ID, if there is a request ID column
+func (c *csvParser) parseRow(number int, row []string) (Row, error) {
+	uri := row[urlColNum]
+	channelURL, err := c.validate(uri)
 	if err != nil {
-		log.Fatalf("unable to close file handler: %s\n", err.Error())
+		return Row{}, err
 	}
+	parsed := Row{Number: number, URL: uri, Channel: channelURL.Key()}
+	if c.requestIDColumn >= 0 {
+		if c.requestIDColumn >= len(row) || strings.TrimSpace(row[c.requestIDColumn]) == "" {
+			return Row{}, fmt.Errorf("missing request ID at column %d", c.requestIDColumn)
+		}
+		parsed.RequestID = strings.TrimSpace(row[c.requestIDColumn])
+	}
+	return parsed, nil
 }
 
-// validate if a URL is formatted correctly and is for the defined URL domain
-func (c *csvParser) validate(uri string) bool {
+// validate if a URL is formatted correctly, is for the defined URL domain and is a supported channel store URL
+func (c *csvParser) validate(uri string) (*rokuurl.ChannelURL, error) {
 	_, err := url.ParseRequestURI(uri)
 	if err != nil {
-		return false
+		return nil, err
 	}
 	if !strings.Contains(uri, c.urlDomain) {
-		return false
+		return nil, &rokuurl.Error{URL: uri, Err: rokuurl.ErrUnsupportedHost}
 	}
-	return true
+	return rokuurl.Parse(uri)
 }
\ No newline at end of file
@@ -0,0 +1,164 @@
+package progress
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tracker counts the progress of a run, it is shared by the parser and the sender
+type Tracker interface {
+	// SetTotal set the number of rows to be processed, used to estimate the remaining time
+	SetTotal(total int64)
+	Parsed()
+	// Skipped row accepted by a resumed run
+	Skipped()
+	// Duplicated row with the same channel of a previous row, or recently queued by the listener for another
+	// request, the duplicated rows do not have results for the job
+	Duplicated()
+	Invalid()
+	Sent()
+	Accepted()
+	Retried()
+	Rejected()
+	// SetResults set the number of stored results of the run
+	SetResults(succeeded int64, failed int64)
+	Snapshot() Snapshot
+}
+
+type tracker struct {
+	start      time.Time
+	total      int64
+	parsed     int64
+	skipped    int64
+	duplicated int64
+	invalid    int64
+	sent       int64
+	accepted   int64
+	retried    int64
+	rejected   int64
+
+	mu        sync.Mutex
+	results   bool
+	succeeded int64
+	failed    int64
+}
+
+// NewTracker returns a tracker starting now
+func NewTracker() Tracker {
+	return &tracker{start: time.Now()}
+}
+
+func (t *tracker) SetTotal(total int64) {
+	atomic.StoreInt64(&t.total, total)
+}
+
+func (t *tracker) Parsed() {
+	atomic.AddInt64(&t.parsed, 1)
+}
+
+func (t *tracker) Skipped() {
+	atomic.AddInt64(&t.skipped, 1)
+}
+
+func (t *tracker) Duplicated() {
+	atomic.AddInt64(&t.duplicated, 1)
+}
+
+func (t *tracker) Invalid() {
+	atomic.AddInt64(&t.invalid, 1)
+}
+
+func (t *tracker) Sent() {
+	atomic.AddInt64(&t.sent, 1)
+}
+
+func (t *tracker) Accepted() {
+	atomic.AddInt64(&t.accepted, 1)
+}
+
+func (t *tracker) Retried() {
+	atomic.AddInt64(&t.retried, 1)
+}
+
+func (t *tracker) Rejected() {
+	atomic.AddInt64(&t.rejected, 1)
+}
+
+func (t *tracker) SetResults(succeeded int64, failed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.results = true
+	t.succeeded = succeeded
+	t.failed = failed
+}
+
+func (t *tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	results, succeeded, failed := t.results, t.succeeded, t.failed
+	t.mu.Unlock()
+	return Snapshot{
+		Elapsed:    time.Since(t.start),
+		Total:      atomic.LoadInt64(&t.total),
+		Parsed:     atomic.LoadInt64(&t.parsed),
+		Skipped:    atomic.LoadInt64(&t.skipped),
+		Duplicated: atomic.LoadInt64(&t.duplicated),
+		Invalid:    atomic.LoadInt64(&t.invalid),
+		Sent:       atomic.LoadInt64(&t.sent),
+		Accepted:   atomic.LoadInt64(&t.accepted),
+		Retried:    atomic.LoadInt64(&t.retried),
+		Rejected:   atomic.LoadInt64(&t.rejected),
+		Results:    results,
+		Succeeded:  succeeded,
+		Failed:     failed,
+	}
+}
+
+// Snapshot progress counters at a point of time
+type Snapshot struct {
+	Elapsed time.Duration
+	// Total rows to be processed, zero if unknown
+	Total      int64
+	Parsed     int64
+	Skipped    int64
+	Duplicated int64
+	Invalid    int64
+	Sent       int64
+	Accepted   int64
+	Retried    int64
+	Rejected   int64
+	// Results whether the succeeded and failed counts are available
+	Results   bool
+	Succeeded int64
+	Failed    int64
+}
+
+// Done rows already processed: accepted, rejected, invalid, duplicated or skipped
+func (s Snapshot) Done() int64 {
+	return s.Accepted + s.Rejected + s.Invalid + s.Duplicated + s.Skipped
+}
+
+// Expected job results: the accepted rows, including the ones accepted by a resumed run
+func (s Snapshot) Expected() int64 {
+	return s.Accepted + s.Skipped
+}
+
+// Rate URLs sent per second
+func (s Snapshot) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Sent) / s.Elapsed.Seconds()
+}
+
+// ETA estimated time to process the remaining rows, zero if it is unknown. The skipped rows are not taken into
+// account to estimate the time per row
+func (s Snapshot) ETA() time.Duration {
+	done := s.Done() - s.Skipped
+	remaining := s.Total - s.Done()
+	if s.Total <= 0 || done <= 0 || remaining <= 0 {
+		return 0
+	}
+	perRow := s.Elapsed / time.Duration(done)
+	return perRow * time.Duration(remaining)
+}
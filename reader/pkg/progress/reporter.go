@@ -0,0 +1,125 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"io"
+	"strings"
+	"time"
+)
+
+// refreshInterval time between the redraws of the terminal view
+const refreshInterval = 250 * time.Millisecond
+
+// Reporter shows the progress of a run
+type Reporter interface {
+	// Run report the progress until the context is done, then report the summary of the run
+	Run(ctx context.Context)
+}
+
+type reporter struct {
+	tracker  Tracker
+	w        io.Writer
+	console  bool
+	interval time.Duration
+}
+
+// NewReporter returns a reporter that redraws a view on the terminal if console is set, otherwise it logs the
+// progress every interval
+func NewReporter(tracker Tracker, w io.Writer, console bool, interval time.Duration) Reporter {
+	return &reporter{
+		tracker:  tracker,
+		w:        w,
+		console:  console,
+		interval: interval,
+	}
+}
+
+func (r *reporter) Run(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	interval := r.interval
+	if r.console {
+		interval = refreshInterval
+		fmt.Fprint(r.w, "\033[H\033[2J")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			snapshot := r.tracker.Snapshot()
+			if r.console {
+				r.draw(snapshot)
+			}
+			logger.Info("run summary", keyvals(snapshot)...)
+			return
+		case <-ticker.C:
+			snapshot := r.tracker.Snapshot()
+			if r.console {
+				r.draw(snapshot)
+				continue
+			}
+			logger.Info("progress", keyvals(snapshot)...)
+		}
+	}
+}
+
+// draw the view at the top of the terminal, each line is cleared since the values length change
+func (r *reporter) draw(s Snapshot) {
+	var b strings.Builder
+	b.WriteString("\033[H")
+	line := func(format string, args ...interface{}) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString("\033[K\n")
+	}
+	line("Elapsed    %s", s.Elapsed.Round(time.Second))
+	if s.Total > 0 {
+		line("Rows       %d / %d (%.1f%%)", s.Done(), s.Total, 100*float64(s.Done())/float64(s.Total))
+	} else {
+		line("Rows       %d", s.Done())
+	}
+	line("Parsed     %d", s.Parsed)
+	if s.Skipped > 0 {
+		line("Skipped    %d", s.Skipped)
+	}
+	line("Sent       %d (%.1f/s)", s.Sent, s.Rate())
+	line("Accepted   %d", s.Accepted)
+	line("Retried    %d", s.Retried)
+	line("Rejected   %d", s.Rejected)
+	line("Invalid    %d", s.Invalid)
+	line("Duplicated %d", s.Duplicated)
+	if eta := s.ETA(); eta > 0 {
+		line("ETA        %s", eta.Round(time.Second))
+	} else {
+		line("ETA        -")
+	}
+	if s.Results {
+		line("Results    %d / %d", s.Succeeded+s.Failed, s.Expected())
+		line("Succeeded  %d", s.Succeeded)
+		line("Failed     %d", s.Failed)
+	}
+	fmt.Fprint(r.w, b.String())
+}
+
+// keyvals the snapshot as log fields
+func keyvals(s Snapshot) []interface{} {
+	kv := []interface{}{
+		"elapsed", s.Elapsed.Round(time.Second).String(),
+		"total", s.Total,
+		"parsed", s.Parsed,
+		"skipped", s.Skipped,
+		"sent", s.Sent,
+		"accepted", s.Accepted,
+		"retried", s.Retried,
+		"rejected", s.Rejected,
+		"invalid", s.Invalid,
+		"duplicated", s.Duplicated,
+		"rate", fmt.Sprintf("%.1f", s.Rate()),
+		"eta", s.ETA().Round(time.Second).String(),
+	}
+	if s.Results {
+		kv = append(kv, "expected", s.Expected(), "succeeded", s.Succeeded, "failed", s.Failed)
+	}
+	return kv
+}
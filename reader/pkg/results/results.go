@@ -0,0 +1,95 @@
+package results
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
+	"github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/StevenRojas/natscrawler/reader/pkg/progress"
+	_ "github.com/lib/pq"
+	"time"
+)
+
+// Counter counts the results stored by the crawlers
+type Counter interface {
+	// Count returns the succeeded and failed URLs of a job
+	Count(ctx context.Context, jobID string) (int64, int64, error)
+	// Watch update the tracker with the job results every interval until the context is done
+	Watch(ctx context.Context, jobID string, tracker progress.Tracker, interval time.Duration)
+	Close() error
+}
+
+type counter struct {
+	db *sql.DB
+}
+
+// NewCounter returns a counter reading the crawlers database
+func NewCounter(dbConf config.Database) (Counter, error) {
+	conn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		dbConf.Host,
+		dbConf.Username,
+		dbConf.Password,
+		dbConf.Database,
+		dbConf.Port,
+		dbConf.SSLMode,
+	)
+	db, err := sql.Open("postgres", conn)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &counter{db: db}, nil
+}
+
+func (c *counter) Count(ctx context.Context, jobID string) (int64, int64, error) {
+	statement := `SELECT success, count(*) FROM public.ulrinfo WHERE job_id = $1 GROUP BY success;`
+	rows, err := c.db.QueryContext(ctx, statement, jobID)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+	var succeeded, failed int64
+	for rows.Next() {
+		var success bool
+		var count int64
+		err = rows.Scan(&success, &count)
+		if err != nil {
+			return 0, 0, err
+		}
+		if success {
+			succeeded = count
+		} else {
+			failed = count
+		}
+	}
+	return succeeded, failed, rows.Err()
+}
+
+func (c *counter) Watch(ctx context.Context, jobID string, tracker progress.Tracker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		succeeded, failed, err := c.Count(ctx, jobID)
+		if err != nil {
+			if ctx.Err() == nil {
+				logging.FromContext(ctx).Warn("unable to count the job results", "error", err)
+			}
+			continue
+		}
+		tracker.SetResults(succeeded, failed)
+	}
+}
+
+func (c *counter) Close() error {
+	return c.db.Close()
+}
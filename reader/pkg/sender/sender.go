@@ -2,74 +2,251 @@ package sender
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/StevenRojas/natscrawler/common/pkg/certs"
+	"github.com/StevenRojas/natscrawler/common/pkg/logging"
 	"github.com/StevenRojas/natscrawler/grpcapi/pkg/crawler/pb"
 	"github.com/StevenRojas/natscrawler/reader/config"
+	"github.com/StevenRojas/natscrawler/reader/pkg/checkpoint"
+	"github.com/StevenRojas/natscrawler/reader/pkg/parser"
+	"github.com/StevenRojas/natscrawler/reader/pkg/progress"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
-	"log"
-	"sync"
+	"google.golang.org/grpc/status"
+	"net"
+	"time"
+)
+
+// outcome of sending a URL to the listener
+type outcome int
+
+const (
+	rejected outcome = iota
+	accepted
+	// duplicated URL recently queued by another request, its result is not stored for the job
+	duplicated
 )
 
 type Sender interface {
-	Start(ctx context.Context, wg*sync.WaitGroup, rowCh <-chan string)
+	Start(ctx context.Context, rowCh <-chan parser.Row) error
 }
 
 type sender struct {
-	grpcConn     *grpc.ClientConn
-	grpcCancelFn context.CancelFunc
+	grpcConn   *grpc.ClientConn
+	conf       config.GrpcServer
+	tracker    progress.Tracker
+	checkpoint checkpoint.Checkpoint
+	general    config.General
+	// namespace of the request IDs derived from the URLs, the job ID
+	namespace uuid.UUID
 }
 
-// NewGrpcService returns a GRPC service instance
-func NewGrpcService(ctx context.Context, conf config.GrpcServer) (Sender, error) {
-	ctx, cancel := context.WithTimeout(ctx, conf.DialTimeout)
-	conn, err := grpc.DialContext(
-		ctx,
-		conf.ListenerAddress,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+// NewGrpcService returns a GRPC service instance, the requests are built as set by the general configuration
+func NewGrpcService(ctx context.Context, conf config.GrpcServer, general config.General, tracker progress.Tracker,
+	checkpoint checkpoint.Checkpoint) (Sender, error) {
+	namespace, err := uuid.Parse(checkpoint.JobID())
+	if err != nil && general.RequestIDs == config.RequestIDsURL {
+		return nil, fmt.Errorf("the job ID must be a UUID to derive the request IDs: %w", err)
+	}
+	conn, err := Dial(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &sender{
+		grpcConn:   conn,
+		conf:       conf,
+		tracker:    tracker,
+		checkpoint: checkpoint,
+		general:    general,
+		namespace:  namespace,
+	}, nil
+}
+
+// Dial connect to the listener, blocking until the connection is ready or the dial timeout expires
+func Dial(ctx context.Context, conf config.GrpcServer) (*grpc.ClientConn, error) {
+	if conf.Token != "" && !conf.TLS.Enabled {
+		return nil, errors.New("the token can only be sent with tls enabled")
+	}
+	creds, err := transportCredentials(conf)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time:                conf.PingInterval,
 			Timeout:             conf.PingTimeout,
 			PermitWithoutStream: true,
 		}),
 		grpc.WithBlock(),
-	)
+	}
+	if conf.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(conf.Token)))
+	}
+	ctx, cancel := context.WithTimeout(ctx, conf.DialTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, conf.ListenerAddress, opts...)
 	if err != nil {
-		cancel()
 		return nil, fmt.Errorf("grpcapi connection error: %v", err)
 	}
+	return conn, nil
+}
 
-	return &sender{
-		grpcConn:     conn,
-		grpcCancelFn: cancel,
-	}, nil
+// transportCredentials returns the TLS credentials if it is enabled, the certificates are reloaded when they change
+func transportCredentials(conf config.GrpcServer) (credentials.TransportCredentials, error) {
+	if !conf.TLS.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	store, err := certs.NewStore(conf.TLS.CertFile, conf.TLS.KeyFile, conf.TLS.CAFile, conf.TLS.ReloadInterval)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load GRPC client certificates: %w", err)
+	}
+	serverName := conf.TLS.ServerName
+	if serverName == "" {
+		serverName, _, err = net.SplitHostPort(conf.ListenerAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return credentials.NewTLS(store.ClientConfig(conf.TLS.ServerName, serverName)), nil
+}
+
+// bearerToken per RPC credentials sending the token in the authorization metadata
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
 }
 
-// Start listening for urls from the channel and send them to the Crawler service using GRPC
-func (s sender) Start(ctx context.Context, wg*sync.WaitGroup, urlCh <-chan string) {
-	defer wg.Done()
+// RequireTransportSecurity the token is never sent in plain text
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// Start listening for rows from the channel and send them to the Crawler service using GRPC, skipping the rows
+// accepted by a resumed run. It returns an error if the URLs can not be sent anymore, i.e.: the listener is
+// unavailable or the client is not authorized
+func (s sender) Start(ctx context.Context, rowCh <-chan parser.Row) error {
 	client := pb.NewCrawlerServiceClient(s.grpcConn)
-	for url := range urlCh {
-		select {
-		case <-ctx.Done():
-			return
-		default:
+	for row := range rowCh {
+		if ctx.Err() != nil {
+			return nil
 		}
-		response, err := client.ProcessUrl(ctx, &pb.UrlRequest{
-			RequestId: uuid.New().String(),
-			Url: url,
-		})
+		if _, ok := s.checkpoint.Accepted(row.Number); ok {
+			s.tracker.Skipped()
+			continue
+		}
+		if _, ok := s.checkpoint.Duplicated(row.Number); ok {
+			s.tracker.Duplicated()
+			continue
+		}
+		requestID, result, err := s.send(ctx, client, s.requestID(row), row.URL)
 		if err != nil {
-			log.Fatalf("error sending URL to Crawler service: %s\n", err.Error())
+			return err
 		}
-		switch response.Status {
-		case pb.UrlResponse_STATUS_UNAVAILABLE:
-			log.Fatalf("the Crawler service is not ablailable to process requests\n")
-		case pb.UrlResponse_STATUS_RETRY:
-			log.Printf("the Crawler service is asking for retry the current URL: %s\n", url)
+		switch {
+		case result == duplicated:
+			s.checkpoint.AckDuplicated(row.Number, requestID)
+		// an interrupted row is sent again when the run is resumed
+		case result == rejected && ctx.Err() != nil:
+			return nil
+		default:
+			s.checkpoint.Ack(row.Number, requestID, result == accepted)
 		}
 	}
-	log.Println("Sender is done")
+	logging.FromContext(ctx).Info("sender is done")
+	return nil
+}
+
+// requestID returns the request ID of the row
+func (s sender) requestID(row parser.Row) string {
+	switch s.general.RequestIDs {
+	case config.RequestIDsURL:
+		return uuid.NewSHA1(s.namespace, []byte(row.Channel)).String()
+	case config.RequestIDsColumn:
+		return row.RequestID
+	default:
+		return uuid.New().String()
+	}
+}
+
+// send a URL, sending it again while the listener asks for a retry up to the max retries. It returns the request ID
+// crawling the URL and the outcome, a URL that can not be sent is counted as rejected. A URL recently queued by
+// another request is duplicated, with the request ID that queued it
+func (s sender) send(ctx context.Context, client pb.CrawlerServiceClient, requestID string,
+	url string) (string, outcome, error) {
+	logger := logging.FromContext(ctx).With("request_id", requestID, "url", url)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			s.tracker.Retried()
+			select {
+			case <-time.After(time.Duration(attempt) * s.conf.RetryBackoff):
+			case <-ctx.Done():
+				return requestID, rejected, nil
+			}
+		}
+		s.tracker.Sent()
+		response, err := s.processURL(ctx, client, requestID, url)
+		if ctx.Err() != nil {
+			return requestID, rejected, nil
+		}
+		retry := false
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.Unavailable, codes.Unauthenticated, codes.PermissionDenied:
+			return requestID, rejected, fmt.Errorf("unable to send URLs to the Crawler service: %w", err)
+		case codes.ResourceExhausted, codes.DeadlineExceeded:
+			retry = true
+		default:
+			s.tracker.Rejected()
+			logger.Error("error sending URL to Crawler service", "error", err)
+			return requestID, rejected, nil
+		}
+		if err == nil {
+			switch response.Status {
+			case pb.UrlResponse_STATUS_ACCEPTED:
+				s.tracker.Accepted()
+				logger.Debug("URL sent")
+				return requestID, accepted, nil
+			case pb.UrlResponse_STATUS_DUPLICATE:
+				s.tracker.Duplicated()
+				logger.Debug("URL already queued", "queued_request_id", response.RequestId)
+				return response.RequestId, duplicated, nil
+			case pb.UrlResponse_STATUS_UNAVAILABLE:
+				return requestID, rejected, fmt.Errorf("the Crawler service is not available to process requests")
+			case pb.UrlResponse_STATUS_RETRY:
+				retry = true
+			default:
+				s.tracker.Rejected()
+				logger.Error("unexpected response status", "status", response.Status.String())
+				return requestID, rejected, nil
+			}
+		}
+		if !retry || attempt >= s.conf.MaxRetries {
+			s.tracker.Rejected()
+			logger.Warn("URL rejected after retries", "attempts", attempt+1, "error", err)
+			return requestID, rejected, nil
+		}
+		logger.Debug("retrying URL", "attempt", attempt+1, "error", err)
+	}
+}
+
+// processURL send the URL to the listener within the request timeout, if there is one
+func (s sender) processURL(ctx context.Context, client pb.CrawlerServiceClient, requestID string,
+	url string) (*pb.UrlResponse, error) {
+	if s.conf.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.conf.RequestTimeout)
+		defer cancel()
+	}
+	return client.ProcessUrl(ctx, &pb.UrlRequest{
+		RequestId: requestID,
+		Url:       url,
+		Force:     s.general.Force,
+	})
 }